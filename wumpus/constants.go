@@ -9,10 +9,6 @@
  */
  package main
 
-import (
-	"machine"
-)
-
 /*
  * CONSTANTS
  */
@@ -21,18 +17,21 @@ const (
 	textWin  string = "    You defeate the Wumpus!    "
 	textLose string = "    The Wumpus killed you!    "
 	textFell string = "    You fell to your death    "
+	textShot string = "    Your arrow hit you!    "
+	textNoArrows string = "    You ran out of arrows    "
 	textIntro string = "    HUNT THE WUMPUS    "
 
 	ON  bool = true
 	OFF bool = false
 
-	// GPIO pins
-	PIN_SDA     machine.Pin = machine.GP8
-	PIN_SCL     machine.Pin = machine.GP9
-	PIN_GREEN   machine.Pin = machine.GP20
-	PIN_RED     machine.Pin = machine.GP21
-	PIN_SPEAKER machine.Pin = machine.GP16
-	PIN_BUTTON  machine.Pin = machine.GP19
+	// Hardware failures. On the Pico, the onboard LED
+	// flashes the failure's number over and over
+	FAIL_NONE     uint = 0
+	FAIL_I2C      uint = 1
+	FAIL_DISPLAY  uint = 2
+	FAIL_ADC      uint = 3
+	FAIL_TERMINAL uint = 4
+	FAIL_SPI      uint = 5
 
 	// Joystick active range
 	UPPER_LIMIT uint16 = 50000
@@ -41,18 +40,56 @@ const (
 	// Fire button debounce check timie
 	DEBOUNCE_TIME_MS int64 = 10
 
-	// Map markers
-	PIT    uint8 = 'p'
-	BAT    uint8 = 'b'
-	WUMPUS uint8 = 'w'
-	EMPTY  uint8 = '#'
+	PLAYER_PIXEL_FLASH_PERIOD_MS int64 = 200
 
-	// Directions
-	UP    uint = 0
-	DOWN  uint = 1
-	LEFT  uint = 2
-	RIGHT uint = 3
-	NONE  uint = 99
+	// How long the arrow count is shown
+	ARROW_COUNT_PERIOD_MS uint32 = 600
 
-	PLAYER_PIXEL_FLASH_PERIOD_MS int64 = 200
+	// How long a bicolor matrix shows the cave after the player dies
+	DEATH_MAP_PERIOD_MS uint32 = 2000
+
+	// How often a greyscale matrix is refreshed
+	GREY_REFRESH_PERIOD_MS uint32 = 2
+
+	// Seed codes are shown as eight hex digits
+	SEED_DIGITS string = "0123456789ABCDEF"
+
+	// The initials that stand for the caves in game codes:
+	// the grid and the dodecahedron
+	CAVE_NAMES string = "GD"
+
+	// Difficulty levels, and the initials that stand for them
+	LEVEL_EASY   uint   = 0
+	LEVEL_NORMAL uint   = 1
+	LEVEL_HARD   uint   = 2
+	LEVEL_CUSTOM uint   = 3
+	LEVEL_NAMES  string = "ENHC"
+
+	// The custom rules, by initial: Bats, Pits, Arrows,
+	// Wumpus roams, Senses kept, bat Roosts
+	CUSTOM_SETTINGS string = "BPAWSR"
+
+	// Optional second display for the session stats: none, a 7-segment
+	// or a 14-segment backpack, at its own I2C address
+	STATS_NONE      uint  = 0
+	STATS_SEGMENT7  uint  = 1
+	STATS_SEGMENT14 uint  = 2
+	STATS_ADDRESS   uint8 = 0x71
+
+	// How long each stat is shown before the next
+	STATS_PAGE_PERIOD_MS int64 = 2000
+)
+
+/*
+ * The room view used for caves that aren't grids: the room, and
+ * its tunnels indexed by direction, as x, y, width and height
+ */
+var (
+	ROOM_BLOCK   [4]uint    = [4]uint{3, 1, 2, 2}
+	ROOM_TUNNELS [4][4]uint = [4][4]uint{
+		{3, 3, 2, 5}, // Up
+		{0, 0, 0, 0}, // Down -- there is no tunnel this way
+		{0, 1, 3, 2}, // Left
+		{5, 1, 3, 2}, // Right
+	}
 )
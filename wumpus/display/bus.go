@@ -0,0 +1,62 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"sync"
+)
+
+/*
+ * The host I2C bus, as the I2C display drivers use it.
+ * TinyGo's `*machine.I2C` satisfies this
+ */
+type Bus interface {
+	Tx(addr uint16, w, r []byte) error
+}
+
+/*
+ * An I2C bus shared by displays driven from more than one goroutine,
+ * eg. a greyscale matrix kept refreshed by a `Refresher` while the
+ * game sets its brightness, scans its keys and writes the stats
+ * display. Give every display on the bus the same `SharedBus`, and
+ * each transaction has the bus to itself
+ */
+type SharedBus struct {
+	bus  Bus
+	lock sync.Mutex
+}
+
+/*
+ * @brief Convenience method to instantiate a SharedBus struct.
+ *
+ * @param bus: The host I2C bus.
+ */
+func NewSharedBus(bus Bus) *SharedBus {
+
+	return &SharedBus{bus: bus}
+}
+
+/*
+ * @brief Write to and read from a device, holding the bus
+ *        until both are done.
+ *
+ * @param addr: The device's 7-bit I2C address.
+ * @param w:    The bytes to write, or `nil`.
+ * @param r:    Space for the bytes to read, or `nil`.
+ *
+ * @returns The bus error, if any.
+ */
+func (b *SharedBus) Tx(addr uint16, w, r []byte) error {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.bus.Tx(addr, w, r)
+}
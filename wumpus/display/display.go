@@ -0,0 +1,223 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"time"
+	"wumpus/graphics"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Pixel colours, for displays with more than one.
+	// Yellow lights both the green and red LEDs
+	COLOUR_OFF    uint = 0
+	COLOUR_GREEN  uint = 1
+	COLOUR_RED    uint = 2
+	COLOUR_YELLOW uint = 3
+
+	// More colours, for full-colour displays only
+	COLOUR_BLUE  uint = 4
+	COLOUR_WHITE uint = 5
+	COLOUR_COUNT uint = 6
+
+	// Pixel intensities, for displays with greyscale
+	GREY_OFF    uint = 0
+	GREY_DIM    uint = 1
+	GREY_MEDIUM uint = 2
+	GREY_FULL   uint = 3
+
+	// Blink rates, for displays that can blink
+	BLINK_OFF    uint = 0
+	BLINK_2HZ    uint = 1
+	BLINK_1HZ    uint = 2
+	BLINK_HALFHZ uint = 3
+)
+
+/*
+ * An 8x8 display: everything the game draws with. (0,0) is the
+ * bottom left corner. Drawing goes to a frame buffer, which `Draw()`
+ * sends to the display; `DrawSprite()` and `Print()` send it themselves
+ */
+type Display interface {
+	Clear()
+	Plot(x uint, y uint, isSet bool)
+	Draw() error
+	DrawSprite(sprite *graphics.Sprite) error
+	Print(text string) error
+	AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error
+	SetBrightness(brightness uint) error
+}
+
+/*
+ * A display whose pixels can be green, red or yellow
+ */
+type ColourDisplay interface {
+	Display
+	IsBicolor() bool
+	PlotColour(x uint, y uint, colour uint)
+	DrawColourSprite(sprite *graphics.ColourSprite) error
+}
+
+/*
+ * A display whose pixels can be any of the `COLOUR_*` colours,
+ * not just green, red and yellow
+ */
+type FullColourDisplay interface {
+	ColourDisplay
+	IsFullColour() bool
+}
+
+/*
+ * A display whose pixels can be dimmed. Some need to be refreshed
+ * every few milliseconds to do so, and say so with `NeedsRefresh()`
+ */
+type GreyscaleDisplay interface {
+	Display
+	SetGreyscale(levels uint)
+	GreyLevels() uint
+	PlotGrey(x uint, y uint, level uint)
+	NeedsRefresh() bool
+	Refresh(now time.Time) error
+}
+
+/*
+ * A display that can blink by itself
+ */
+type BlinkingDisplay interface {
+	Display
+	SetBlink(rate uint) error
+}
+
+/*
+ * A display that shows a few characters, such as a segment display
+ */
+type TextDisplay interface {
+	Print(text string) error
+}
+
+/*
+ * A display with room beside the game for a few lines of text,
+ * such as the arrows left and what the player can sense
+ */
+type StatusDisplay interface {
+	Display
+	ShowStatus(lines []string) error
+}
+
+/*
+ * @brief Does a display show colour?
+ *
+ * @param d: The display.
+ *
+ * @returns `true` if the display is a bicolor one, otherwise `false`.
+ */
+func IsBicolor(d Display) bool {
+
+	colourDisplay, ok := d.(ColourDisplay)
+	return ok && colourDisplay.IsBicolor()
+}
+
+/*
+ * @brief Does a display show every colour?
+ *
+ * @param d: The display.
+ *
+ * @returns `true` if the display is a full-colour one, otherwise `false`.
+ */
+func IsFullColour(d Display) bool {
+
+	fullColourDisplay, ok := d.(FullColourDisplay)
+	return ok && fullColourDisplay.IsFullColour()
+}
+
+/*
+ * @brief Set a pixel's colour. On a display without colour, any
+ *        colour but `COLOUR_OFF` lights the pixel.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param d:      The display.
+ * @param x:      The pixel's X co-ordinate.
+ * @param y:      The pixel's Y co-ordinate.
+ * @param colour: `COLOUR_OFF`, `COLOUR_GREEN`, `COLOUR_RED` or `COLOUR_YELLOW`,
+ *                or, on a full-colour display, `COLOUR_BLUE` or `COLOUR_WHITE`.
+ */
+func PlotColour(d Display, x uint, y uint, colour uint) {
+
+	if colourDisplay, ok := d.(ColourDisplay); ok {
+		colourDisplay.PlotColour(x, y, colour)
+		return
+	}
+
+	d.Plot(x, y, colour != COLOUR_OFF)
+}
+
+/*
+ * @brief Write a colour graphic to the frame buffer and update the
+ *        display. On a display without colour, both layers are shown.
+ *
+ * @param d:      The display.
+ * @param sprite: The graphic.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func DrawColourSprite(d Display, sprite *graphics.ColourSprite) error {
+
+	if colourDisplay, ok := d.(ColourDisplay); ok {
+		return colourDisplay.DrawColourSprite(sprite)
+	}
+
+	frame := graphics.Sprite{}
+	for i := range frame {
+		frame[i] = sprite.Green[i] | sprite.Red[i]
+	}
+
+	return d.DrawSprite(&frame)
+}
+
+/*
+ * @brief Set a pixel's intensity. On a display without
+ *        greyscale, any intensity but `GREY_OFF` lights the pixel.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param d:     The display.
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param level: `GREY_OFF`, `GREY_DIM`, `GREY_MEDIUM` or `GREY_FULL`.
+ */
+func PlotGrey(d Display, x uint, y uint, level uint) {
+
+	if greyDisplay, ok := d.(GreyscaleDisplay); ok {
+		greyDisplay.PlotGrey(x, y, level)
+		return
+	}
+
+	d.Plot(x, y, level != GREY_OFF)
+}
+
+/*
+ * @brief Set a display's blink rate. Displays that can't
+ *        blink stay as they are.
+ *
+ * @param d:    The display.
+ * @param rate: `BLINK_OFF`, `BLINK_2HZ`, `BLINK_1HZ` or `BLINK_HALFHZ`.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func SetBlink(d Display, rate uint) error {
+
+	if blinkingDisplay, ok := d.(BlinkingDisplay); ok {
+		return blinkingDisplay.SetBlink(rate)
+	}
+
+	return nil
+}
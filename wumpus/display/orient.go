@@ -0,0 +1,88 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Orientations: how far an 8x8 display is turned
+	// clockwise from the README's circuit
+	ROTATE_0   uint = 0
+	ROTATE_90  uint = 1
+	ROTATE_180 uint = 2
+	ROTATE_270 uint = 3
+)
+
+/*
+ * @brief Apply an orientation to an 8x8 frame: mirror it, then
+ *        turn it anticlockwise to undo the display's rotation.
+ *
+ * @param buffer:      The frame, one byte per column, bit 0 at the bottom.
+ * @param rotation:    `ROTATE_0`, `ROTATE_90`, `ROTATE_180` or `ROTATE_270`.
+ * @param isMirroredX: `true` to flip the frame left to right.
+ * @param isMirroredY: `true` to flip the frame top to bottom.
+ *
+ * @returns The frame as it should be sent to the display.
+ */
+func Orient(buffer *[8]byte, rotation uint, isMirroredX bool, isMirroredY bool) [8]byte {
+
+	if rotation == ROTATE_0 && !isMirroredX && !isMirroredY {
+		return *buffer
+	}
+
+	frame := [8]byte{}
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			if buffer[x]&(1<<y) == 0 {
+				continue
+			}
+
+			u, v := OrientPixel(x, y, rotation, isMirroredX, isMirroredY)
+			frame[u] |= 1 << v
+		}
+	}
+
+	return frame
+}
+
+/*
+ * @brief Apply an orientation to a pixel of an 8x8 frame,
+ *        as `Orient()` does to the whole frame.
+ *
+ * @param x:           The pixel's X co-ordinate.
+ * @param y:           The pixel's Y co-ordinate.
+ * @param rotation:    `ROTATE_0`, `ROTATE_90`, `ROTATE_180` or `ROTATE_270`.
+ * @param isMirroredX: `true` to flip the frame left to right.
+ * @param isMirroredY: `true` to flip the frame top to bottom.
+ *
+ * @returns The pixel's co-ordinates on the display.
+ */
+func OrientPixel(x uint, y uint, rotation uint, isMirroredX bool, isMirroredY bool) (uint, uint) {
+
+	if isMirroredX {
+		x = 7 - x
+	}
+
+	if isMirroredY {
+		y = 7 - y
+	}
+
+	switch rotation {
+	case ROTATE_90:
+		x, y = 7-y, x
+	case ROTATE_180:
+		x, y = 7-x, 7-y
+	case ROTATE_270:
+		x, y = y, 7-x
+	}
+
+	return x, y
+}
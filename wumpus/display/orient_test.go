@@ -0,0 +1,55 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+
+	// Where the image's bottom corners land on the display,
+	// which has (0,0) at the bottom left as in the README's circuit
+	tests := []struct {
+		name        string
+		rotation    uint
+		isMirroredX bool
+		isMirroredY bool
+		left        [2]uint
+		right       [2]uint
+	}{
+		{"upright", ROTATE_0, false, false, [2]uint{0, 0}, [2]uint{7, 0}},
+		{"90", ROTATE_90, false, false, [2]uint{7, 0}, [2]uint{7, 7}},
+		{"180", ROTATE_180, false, false, [2]uint{7, 7}, [2]uint{0, 7}},
+		{"270", ROTATE_270, false, false, [2]uint{0, 7}, [2]uint{0, 0}},
+		{"mirror X", ROTATE_0, true, false, [2]uint{7, 0}, [2]uint{0, 0}},
+		{"mirror Y", ROTATE_0, false, true, [2]uint{0, 7}, [2]uint{7, 7}},
+		{"mirror both", ROTATE_0, true, true, [2]uint{7, 7}, [2]uint{0, 7}},
+		{"90, mirror X", ROTATE_90, true, false, [2]uint{7, 7}, [2]uint{7, 0}},
+		{"270, mirror Y", ROTATE_270, false, true, [2]uint{7, 7}, [2]uint{7, 0}},
+	}
+
+	for _, test := range tests {
+		buffer := [8]byte{0x01, 0, 0, 0, 0, 0, 0, 0x01}
+		frame := Orient(&buffer, test.rotation, test.isMirroredX, test.isMirroredY)
+
+		expected := [8]byte{}
+		expected[test.left[0]] |= 1 << test.left[1]
+		expected[test.right[0]] |= 1 << test.right[1]
+		if frame != expected {
+			t.Errorf("%s: got % X, expected % X", test.name, frame, expected)
+		}
+
+		// The frame itself is left as it was
+		if buffer != [8]byte{0x01, 0, 0, 0, 0, 0, 0, 0x01} {
+			t.Errorf("%s: frame changed to % X", test.name, buffer)
+		}
+	}
+}
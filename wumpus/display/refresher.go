@@ -0,0 +1,118 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+ * Keeps a greyscale display refreshed from a ticker in its own
+ * goroutine, so the display stays refreshed however the rest of
+ * the program waits. The ticker's time is passed to `Refresh()`,
+ * and is read from the same clock as `time.Now()`
+ */
+type Refresher struct {
+	// The display, and how often to refresh it
+	display GreyscaleDisplay
+	period  time.Duration
+	// Closed to stop the goroutine
+	stop chan struct{}
+	// The error that stopped the refreshes, if any
+	lock sync.Mutex
+	err  error
+}
+
+/*
+ * @brief Convenience method to instantiate a Refresher struct.
+ *        Call `Start()` to begin refreshing.
+ *
+ * @param display: The display to refresh.
+ * @param period:  The time between refreshes.
+ */
+func NewRefresher(display GreyscaleDisplay, period time.Duration) *Refresher {
+
+	return &Refresher{
+		display: display,
+		period:  period,
+	}
+}
+
+/*
+ * @brief Start refreshing the display, unless it doesn't need it
+ *        or the refreshes have already started.
+ *
+ * @returns `true` if the display is being refreshed, otherwise `false`.
+ */
+func (r *Refresher) Start() bool {
+
+	if !r.display.NeedsRefresh() {
+		return false
+	}
+
+	if r.stop == nil {
+		r.stop = make(chan struct{})
+		go r.run(r.stop)
+	}
+
+	return true
+}
+
+/*
+ * @brief Stop refreshing the display.
+ */
+func (r *Refresher) Stop() {
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+/*
+ * @brief Get the error that stopped the refreshes: they stop
+ *        at the first refresh that fails.
+ *
+ * @returns The error, or `nil` if there hasn't been one.
+ */
+func (r *Refresher) Err() error {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.err
+}
+
+/*
+ * @brief Refresh the display on every tick until stopped,
+ *        or until a refresh fails.
+ *
+ * @param stop: Closed to stop.
+ */
+func (r *Refresher) run(stop chan struct{}) {
+
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := r.display.Refresh(now); err != nil {
+				r.lock.Lock()
+				r.err = err
+				r.lock.Unlock()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+ * A greyscale display that counts its refreshes. Only the
+ * greyscale methods are provided
+ */
+type countingDisplay struct {
+	Display
+	isRefreshed bool
+	lock        sync.Mutex
+	refreshes   int
+	err         error
+}
+
+func (d *countingDisplay) SetGreyscale(levels uint) {}
+
+func (d *countingDisplay) GreyLevels() uint {
+
+	return 4
+}
+
+func (d *countingDisplay) PlotGrey(x uint, y uint, level uint) {}
+
+func (d *countingDisplay) NeedsRefresh() bool {
+
+	return d.isRefreshed
+}
+
+func (d *countingDisplay) Refresh(now time.Time) error {
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.refreshes += 1
+	return d.err
+}
+
+func (d *countingDisplay) count() int {
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.refreshes
+}
+
+func TestRefresherSkipsDisplaysThatHoldTheirImage(t *testing.T) {
+
+	target := countingDisplay{}
+	refresher := NewRefresher(&target, time.Millisecond)
+	if refresher.Start() {
+		t.Fatal("refreshing a display that doesn't need it")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if target.count() != 0 {
+		t.Errorf("%d refreshes, expected none", target.count())
+	}
+}
+
+func TestRefresherRefreshesUntilStopped(t *testing.T) {
+
+	target := countingDisplay{isRefreshed: true}
+	refresher := NewRefresher(&target, time.Millisecond)
+	if !refresher.Start() {
+		t.Fatal("not refreshing")
+	}
+
+	// Refreshes carry on while this goroutine sleeps
+	time.Sleep(20 * time.Millisecond)
+	refresher.Stop()
+	count := target.count()
+	if count == 0 {
+		t.Fatal("no refreshes")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if target.count() > count+1 {
+		t.Errorf("refreshed %d times after stopping", target.count()-count)
+	}
+
+	if refresher.Err() != nil {
+		t.Error(refresher.Err())
+	}
+}
+
+func TestRefresherStopsOnError(t *testing.T) {
+
+	target := countingDisplay{isRefreshed: true, err: errors.New("no ack")}
+	refresher := NewRefresher(&target, time.Millisecond)
+	refresher.Start()
+
+	time.Sleep(20 * time.Millisecond)
+	if target.count() != 1 {
+		t.Errorf("%d refreshes, expected to stop after 1", target.count())
+	}
+
+	if refresher.Err() != target.err {
+		t.Errorf("got error %v, expected %v", refresher.Err(), target.err)
+	}
+
+	refresher.Stop()
+}
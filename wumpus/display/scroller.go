@@ -0,0 +1,239 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"time"
+	"wumpus/graphics"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Scroll directions: the way the text moves
+	SCROLL_LEFT  uint = 0
+	SCROLL_RIGHT uint = 1
+	SCROLL_UP    uint = 2
+	SCROLL_DOWN  uint = 3
+
+	// Time between frames, as used by `HT16K33.Print()`
+	SCROLL_PERIOD_MS int64 = 80
+)
+
+/*
+ * A text message that scrolls across the display one frame at a
+ * time, as its `Step()` method is called, so that the caller can
+ * carry on with other work -- or cut the message short
+ */
+type Scroller struct {
+	// The display to draw on
+	display Display
+	// The text, and the text as a strip of glyphs: columns for
+	// left and right scrolling, rows, top first, for up and down
+	text  string
+	strip []byte
+	// Settings
+	direction uint
+	period    time.Duration
+	loops     uint
+	// Progress: the strip offset of the next frame, the number
+	// of loops completed, and when the last frame was drawn
+	offset    int
+	loop      uint
+	lastFrame time.Time
+	isStarted bool
+	isDone    bool
+}
+
+/*
+ * @brief Convenience method to instantiate a Scroller struct.
+ *        By default, the text scrolls left once, at the same
+ *        speed as the HT16K33 driver's `Print()`.
+ *
+ * @param display: The display to scroll the text across.
+ * @param text:    The text to scroll.
+ */
+func NewScroller(display Display, text string) Scroller {
+
+	scroller := Scroller{
+		display: display,
+		period:  time.Duration(SCROLL_PERIOD_MS) * time.Millisecond,
+		loops:   1,
+	}
+
+	scroller.SetText(text)
+	return scroller
+}
+
+/*
+ * @brief Set the text to scroll, and start again.
+ *
+ * @param text: The text to scroll.
+ */
+func (s *Scroller) SetText(text string) {
+
+	s.text = text
+	s.layOut()
+	s.Reset()
+}
+
+/*
+ * @brief Set the way the text moves, and start again.
+ *
+ * @param direction: `SCROLL_LEFT`, `SCROLL_RIGHT`, `SCROLL_UP` or `SCROLL_DOWN`.
+ */
+func (s *Scroller) SetDirection(direction uint) {
+
+	s.direction = direction
+	s.layOut()
+	s.Reset()
+}
+
+/*
+ * @brief Set the scroll speed.
+ *
+ * @param period: The time each frame is shown for, in ms.
+ */
+func (s *Scroller) SetSpeed(period int64) {
+
+	s.period = time.Duration(period) * time.Millisecond
+}
+
+/*
+ * @brief Set how many times the text scrolls by.
+ *
+ * @param loops: The number of times, or 0 to scroll until stopped.
+ */
+func (s *Scroller) SetLoops(loops uint) {
+
+	s.loops = loops
+}
+
+/*
+ * @brief Go back to the start of the text.
+ */
+func (s *Scroller) Reset() {
+
+	s.offset = 0
+	s.loop = 0
+	s.isStarted = false
+	s.isDone = false
+}
+
+/*
+ * @brief Stop scrolling. The display keeps the last frame drawn.
+ */
+func (s *Scroller) Stop() {
+
+	s.isDone = true
+}
+
+/*
+ * @brief Has the text finished scrolling?
+ *
+ * @returns `true` if the scroll is done or was stopped, otherwise `false`.
+ */
+func (s *Scroller) IsDone() bool {
+
+	return s.isDone
+}
+
+/*
+ * @brief Draw the next frame, if it's due. Call this regularly,
+ *        eg. from a main loop, until `IsDone()` returns `true`.
+ *
+ * @param now: The current time.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Scroller) Step(now time.Time) error {
+
+	if s.isDone {
+		return nil
+	}
+
+	if s.isStarted && now.Sub(s.lastFrame) < s.period {
+		return nil
+	}
+
+	// Move on a loop once the last frame has had its time
+	frames := len(s.strip) - 7
+	if s.offset >= frames {
+		s.loop += 1
+		if s.loops > 0 && s.loop >= s.loops {
+			s.isDone = true
+			return nil
+		}
+
+		s.offset = 0
+	}
+
+	s.isStarted = true
+	s.lastFrame = now
+	frame := s.frame(s.offset)
+	s.offset += 1
+	return s.display.DrawSprite(&frame)
+}
+
+/*
+ * @brief Render the text as a strip to suit the scroll direction.
+ */
+func (s *Scroller) layOut() {
+
+	if s.direction == SCROLL_UP || s.direction == SCROLL_DOWN {
+		s.strip = textRows(s.text)
+		for len(s.strip) < 8 {
+			s.strip = append(s.strip, 0x00)
+		}
+	} else {
+		s.strip = TextColumns(s.text)
+		if len(s.strip) <= 8 {
+			// Fill one frame, with the text in the middle
+			s.strip = CentreColumns(s.strip, 8)
+		}
+	}
+}
+
+/*
+ * @brief Cut a frame from the strip.
+ *
+ * @param offset: How many frames into the scroll this is.
+ *
+ * @returns The frame.
+ */
+func (s *Scroller) frame(offset int) graphics.Sprite {
+
+	frame := graphics.Sprite{}
+	last := len(s.strip) - 8
+	switch s.direction {
+	case SCROLL_LEFT:
+		copy(frame[:], s.strip[offset:offset+8])
+	case SCROLL_RIGHT:
+		copy(frame[:], s.strip[last-offset:last-offset+8])
+	case SCROLL_UP, SCROLL_DOWN:
+		// Rows run from the top of the text down
+		start := offset
+		if s.direction == SCROLL_DOWN {
+			start = last - offset
+		}
+
+		for y := 0; y < 8; y++ {
+			row := s.strip[start+7-y]
+			for x := uint(0); x < 8; x++ {
+				if row&(1<<x) != 0 {
+					frame[x] |= 1 << uint(y)
+				}
+			}
+		}
+	}
+
+	return frame
+}
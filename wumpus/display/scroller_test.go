@@ -0,0 +1,217 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"wumpus/graphics"
+)
+
+/*
+ * A Display that keeps each frame drawn on it
+ */
+type frameDisplay struct {
+	frames [][8]byte
+}
+
+func (d *frameDisplay) Clear()                              {}
+func (d *frameDisplay) Plot(x uint, y uint, isSet bool)     {}
+func (d *frameDisplay) Draw() error                         { return nil }
+func (d *frameDisplay) Print(text string) error             { return nil }
+func (d *frameDisplay) SetBrightness(brightness uint) error { return nil }
+
+func (d *frameDisplay) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error {
+
+	return nil
+}
+
+func (d *frameDisplay) DrawSprite(sprite *graphics.Sprite) error {
+
+	d.frames = append(d.frames, *sprite)
+	return nil
+}
+
+// "HI" as columns: each glyph ends in a blank column
+var testHIColumns []byte = []byte{0xFE, 0x10, 0x10, 0x10, 0xFE, 0x00, 0x82, 0xFE, 0x82, 0x00}
+
+/*
+ * @brief Step a scroller a frame period at a time until it's done,
+ *        collecting each frame it draws.
+ *
+ * @param t:        The test.
+ * @param scroller: The scroller.
+ * @param display:  The display it draws on.
+ * @param limit:    The most frames to step through.
+ *
+ * @returns The frames drawn.
+ */
+func scrollFrames(t *testing.T, scroller *Scroller, display *frameDisplay, limit int) [][8]byte {
+
+	t.Helper()
+	display.frames = [][8]byte{}
+	now := time.Now()
+	for i := 0; i < limit && !scroller.IsDone(); i++ {
+		if err := scroller.Step(now); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(time.Duration(SCROLL_PERIOD_MS) * time.Millisecond)
+	}
+
+	return display.frames
+}
+
+/*
+ * @brief Cut the frames of a left or right scroll from a strip of columns.
+ *
+ * @param columns: The columns.
+ * @param offsets: The offset of each frame.
+ *
+ * @returns The frames.
+ */
+func columnFrames(columns []byte, offsets ...int) [][8]byte {
+
+	frames := [][8]byte{}
+	for _, offset := range offsets {
+		frame := [8]byte{}
+		copy(frame[:], columns[offset:offset+8])
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func TestScrollerLeft(t *testing.T) {
+
+	display := frameDisplay{}
+	scroller := NewScroller(&display, "HI")
+
+	frames := scrollFrames(t, &scroller, &display, 100)
+	if expected := columnFrames(testHIColumns, 0, 1, 2); !reflect.DeepEqual(frames, expected) {
+		t.Errorf("drew % X, expected % X", frames, expected)
+	}
+}
+
+func TestScrollerRight(t *testing.T) {
+
+	display := frameDisplay{}
+	scroller := NewScroller(&display, "HI")
+	scroller.SetDirection(SCROLL_RIGHT)
+
+	frames := scrollFrames(t, &scroller, &display, 100)
+	if expected := columnFrames(testHIColumns, 2, 1, 0); !reflect.DeepEqual(frames, expected) {
+		t.Errorf("drew % X, expected % X", frames, expected)
+	}
+}
+
+func TestScrollerUpAndDown(t *testing.T) {
+
+	// "I" in the middle of the display, and moved up a row
+	middle := [8]byte{0, 0, 0x82, 0xFE, 0x82, 0, 0, 0}
+	raised := [8]byte{0, 0, 0x04, 0xFC, 0x04, 0, 0, 0}
+
+	display := frameDisplay{}
+	scroller := NewScroller(&display, "I")
+	scroller.SetDirection(SCROLL_UP)
+	if frames := scrollFrames(t, &scroller, &display, 100); !reflect.DeepEqual(frames, [][8]byte{middle, raised}) {
+		t.Errorf("scrolling up drew % X", frames)
+	}
+
+	scroller.SetDirection(SCROLL_DOWN)
+	if frames := scrollFrames(t, &scroller, &display, 100); !reflect.DeepEqual(frames, [][8]byte{raised, middle}) {
+		t.Errorf("scrolling down drew % X", frames)
+	}
+}
+
+func TestScrollerLoops(t *testing.T) {
+
+	display := frameDisplay{}
+	scroller := NewScroller(&display, "HI")
+	scroller.SetLoops(3)
+
+	frames := scrollFrames(t, &scroller, &display, 100)
+	expected := columnFrames(testHIColumns, 0, 1, 2, 0, 1, 2, 0, 1, 2)
+	if !reflect.DeepEqual(frames, expected) {
+		t.Errorf("drew % X, expected three loops", frames)
+	}
+
+	// With no loop count, it keeps going until stopped
+	scroller.SetLoops(0)
+	scroller.Reset()
+	if frames := scrollFrames(t, &scroller, &display, 100); len(frames) != 100 {
+		t.Errorf("stopped after %d frames", len(frames))
+	}
+
+	scroller.Stop()
+	if !scroller.IsDone() {
+		t.Error("not done when stopped")
+	}
+}
+
+func TestScrollerWaitsForEachFrame(t *testing.T) {
+
+	display := frameDisplay{}
+	scroller := NewScroller(&display, "HI")
+	scroller.SetSpeed(100)
+
+	start := time.Now()
+	_ = scroller.Step(start)
+	_ = scroller.Step(start.Add(99 * time.Millisecond))
+	if len(display.frames) != 1 {
+		t.Errorf("%d frames drawn before the period was up", len(display.frames))
+	}
+
+	_ = scroller.Step(start.Add(100 * time.Millisecond))
+	if len(display.frames) != 2 {
+		t.Errorf("%d frames drawn, expected 2", len(display.frames))
+	}
+}
+
+func FuzzScroller(f *testing.F) {
+
+	addTextSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		display := frameDisplay{}
+		scroller := NewScroller(&display, text)
+
+		// Left and right, the text scrolls until its end has been
+		// shown; up and down, each character takes nine rows
+		columns := expectedColumns(text)
+		rows := len([]rune(text)) * 9
+		tests := []struct {
+			direction uint
+			length    int
+		}{
+			{SCROLL_LEFT, len(columns)},
+			{SCROLL_RIGHT, len(columns)},
+			{SCROLL_UP, rows},
+			{SCROLL_DOWN, rows},
+		}
+
+		for _, test := range tests {
+			expected := 1
+			if test.length > 8 {
+				expected = test.length - 7
+			}
+
+			scroller.SetDirection(test.direction)
+			frames := scrollFrames(t, &scroller, &display, expected+2)
+			if len(frames) != expected || !scroller.IsDone() {
+				t.Errorf("%q scrolled %d way drew %d frames, expected %d", text, test.direction, len(frames), expected)
+			}
+
+			if expected == 1 && test.direction == SCROLL_LEFT {
+				checkCentred(t, frames[0][:], columns)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"wumpus/graphics"
+)
+
+/*
+ * @brief Render a text string as a series of 8-pixel columns,
+ *        one glyph after another. Characters without a glyph
+ *        are shown as `graphics.UNKNOWN_GLYPH`.
+ *
+ * @param text: The string to render.
+ *
+ * @returns The columns.
+ */
+func TextColumns(text string) []byte {
+
+	columns := []byte{}
+	for _, character := range text {
+		if character == ' ' {
+			// It's a space, so just add two blank columns
+			columns = append(columns, 0x00, 0x00)
+			continue
+		}
+
+		// Write the character's glyph, which ends in a blank column
+		columns = append(columns, glyph(character)...)
+	}
+
+	return columns
+}
+
+/*
+ * @brief Get the glyph for a character.
+ *
+ * @param character: The character.
+ *
+ * @returns The glyph's columns, or `graphics.UNKNOWN_GLYPH`
+ *          if the character set doesn't include it.
+ */
+func glyph(character rune) []byte {
+
+	// The character set keeps the degree sign in place of Ascii 127
+	if character == '°' {
+		character = 127
+	}
+
+	if character < 32 || character > 127 {
+		return graphics.UNKNOWN_GLYPH
+	}
+
+	columns := graphics.CHARSET[character-32]
+	if len(columns) == 0 {
+		return graphics.UNKNOWN_GLYPH
+	}
+
+	return columns
+}
+
+/*
+ * @brief Place a short run of columns in the middle of a frame.
+ *
+ * @param columns: The columns, which may end with blank columns.
+ * @param width:   The frame's width.
+ *
+ * @returns The columns, padded to fill the frame.
+ */
+func CentreColumns(columns []byte, width int) []byte {
+
+	// Ignore trailing blank columns
+	length := len(columns)
+	for length > 0 && columns[length-1] == 0x00 {
+		length -= 1
+	}
+
+	if length > width {
+		length = width
+	}
+
+	frame := make([]byte, width)
+	copy(frame[(width-length)/2:], columns[:length])
+	return frame
+}
+
+/*
+ * @brief Render a text string as a series of 8-pixel rows, top first,
+ *        with each glyph centred in its own 8x8 cell and a blank row
+ *        between glyphs. Bit 0 of each row is the left-most column.
+ *
+ * @param text: The string to render.
+ *
+ * @returns The rows.
+ */
+func textRows(text string) []byte {
+
+	rows := []byte{}
+	for _, character := range text {
+		// Centre the glyph in its cell
+		cell := CentreColumns(glyph(character), 8)
+
+		// Turn the cell's columns into rows, top first
+		for y := 7; y >= 0; y-- {
+			row := byte(0)
+			for x := 0; x < 8; x++ {
+				if cell[x]&(1<<uint(y)) != 0 {
+					row |= 1 << uint(x)
+				}
+			}
+
+			rows = append(rows, row)
+		}
+
+		rows = append(rows, 0x00)
+	}
+
+	return rows
+}
@@ -0,0 +1,117 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package display
+
+import (
+	"bytes"
+	"testing"
+	"wumpus/graphics"
+)
+
+/*
+ * @brief Add text that exercises the character set's edges
+ *        to a fuzz test's seed corpus.
+ *
+ * @param f: The fuzz test.
+ */
+func addTextSeeds(f *testing.F) {
+
+	for _, text := range []string{"", " ", "I", "HI", "HUNT THE WUMPUS", "20°", "~\x7F",
+		"\x00\x1F", "日本", "\xFF\xFE", "Á", "    You defeate the Wumpus!    "} {
+		f.Add(text)
+	}
+}
+
+/*
+ * @brief Render text as columns straight from the character set:
+ *        two blank columns for a space, the box for anything
+ *        the set lacks.
+ *
+ * @param text: The text.
+ *
+ * @returns The columns.
+ */
+func expectedColumns(text string) []byte {
+
+	columns := []byte{}
+	for _, character := range []rune(text) {
+		switch {
+		case character == ' ':
+			columns = append(columns, 0x00, 0x00)
+		case character == '°' || character == 127:
+			columns = append(columns, graphics.CHARSET[95]...)
+		case character > 32 && character < 127:
+			columns = append(columns, graphics.CHARSET[character-32]...)
+		default:
+			columns = append(columns, graphics.UNKNOWN_GLYPH...)
+		}
+	}
+
+	return columns
+}
+
+/*
+ * @brief Check that a frame holds a run of columns in its middle,
+ *        with no more than one more blank column on the right than
+ *        on the left.
+ *
+ * @param t:       The test.
+ * @param frame:   The frame.
+ * @param columns: The columns, which may end with blank columns.
+ */
+func checkCentred(t *testing.T, frame []byte, columns []byte) {
+
+	t.Helper()
+	text := bytes.TrimRight(columns, "\x00")
+	left := (len(frame) - len(text)) / 2
+	expected := make([]byte, len(frame))
+	copy(expected[left:], text)
+	if !bytes.Equal(frame, expected) {
+		t.Errorf("frame % X, expected % X", frame, expected)
+	}
+}
+
+func FuzzTextColumns(f *testing.F) {
+
+	addTextSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		columns := TextColumns(text)
+		if expected := expectedColumns(text); !bytes.Equal(columns, expected) {
+			t.Errorf("%q gave % X, expected % X", text, columns, expected)
+		}
+
+		if len(columns) <= 8 {
+			checkCentred(t, CentreColumns(columns, 8), columns)
+		}
+	})
+}
+
+func FuzzCentreColumns(f *testing.F) {
+
+	f.Add([]byte{}, 8)
+	f.Add([]byte{0x82, 0xFE, 0x82, 0x00, 0x00}, 8)
+	f.Add([]byte{0x00, 0x18, 0x00}, 16)
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, 8)
+	f.Fuzz(func(t *testing.T, columns []byte, width int) {
+		if width < 0 || width > 256 {
+			t.Skip()
+		}
+
+		frame := CentreColumns(columns, width)
+		if len(frame) != width {
+			t.Fatalf("frame is %d wide, expected %d", len(frame), width)
+		}
+
+		// Anything that fits is kept whole, in the middle
+		if len(bytes.TrimRight(columns, "\x00")) <= width {
+			checkCentred(t, frame, columns)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Map markers
+	PIT    uint8 = 'p'
+	BAT    uint8 = 'b'
+	WUMPUS uint8 = 'w'
+	EMPTY  uint8 = '#'
+
+	// Directions
+	UP    uint = 0
+	DOWN  uint = 1
+	LEFT  uint = 2
+	RIGHT uint = 3
+	NONE  uint = 99
+
+	// What the player sensed in a room, as flags
+	FELT_STINK   uint8 = 0x01
+	FELT_DRAUGHT uint8 = 0x02
+	FELT_SOUND   uint8 = 0x04
+)
+
+/*
+ * The result of a player action, for the front end to present
+ */
+type Outcome uint
+
+const (
+	// The player is safe... for now!
+	SAFE Outcome = iota
+	// The player was carried off by a bat
+	GRABBED_BY_BAT
+	// The player fell down a pit
+	FELL_INTO_PIT
+	// The player ran into the Wumpus
+	EATEN_BY_WUMPUS
+	// The player's arrow hit the Wumpus
+	KILLED_WUMPUS
+	// The player's arrow missed, and the noise woke the Wumpus
+	MISSED_WUMPUS
+	// The Wumpus is awake and moved to another room
+	WUMPUS_MOVED
+	// The player's arrow came back round and hit them
+	SHOT_SELF
+	// The player's last arrow missed
+	OUT_OF_ARROWS
+)
+
+const (
+	// How many boards to roll in search of a winnable one
+	MAX_BOARD_ROLLS int = 50
+
+	// How many rolls to spend in search of a free room before
+	// stepping through the cave for one
+	MAX_ROOM_ROLLS int = 100
+
+	// Default quiver size, and how many rooms an arrow can fly
+	ARROWS      uint = 5
+	ARROW_RANGE uint = 5
+
+	// Once awake, the Wumpus moves on three turns in four
+	WUMPUS_MOVE_CHANCE uint = 3
+	WUMPUS_MOVE_ROLL   uint = 4
+
+	// A bat flight wakes the Wumpus one time in two
+	BAT_WAKE_CHANCE uint = 1
+	BAT_WAKE_ROLL   uint = 2
+)
@@ -0,0 +1,85 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+/*
+ * Where the game's rolls come from
+ */
+type Source interface {
+	// Return a value from `start` up to, but not including, `max`
+	Int(start uint, max uint) uint
+}
+
+/*
+ * A seeded pseudo-random number generator (xorshift32). The same
+ * seed always yields the same sequence, so the same seed always
+ * rolls the same board on every platform
+ */
+type Random struct {
+	seed  uint32
+	state uint32
+}
+
+/*
+ * @brief Convenience method to instantiate a Random struct.
+ *
+ * @param seed: The 32-bit seed.
+ */
+func NewRandom(seed uint32) *Random {
+
+	r := Random{seed: seed, state: seed}
+
+	// xorshift never leaves zero, so swap in a fixed, non-zero state
+	if r.state == 0 {
+		r.state = 0x9E3779B9
+	}
+
+	return &r
+}
+
+/*
+ * @brief Get the seed the generator was created with.
+ *
+ * @returns The seed.
+ */
+func (r *Random) Seed() uint32 {
+
+	return r.seed
+}
+
+/*
+ * @brief Get the next raw value in the sequence.
+ *
+ * @returns The value.
+ */
+func (r *Random) Uint32() uint32 {
+
+	r.state ^= r.state << 13
+	r.state ^= r.state >> 17
+	r.state ^= r.state << 5
+	return r.state
+}
+
+/*
+ * @brief Calculate a random number.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: The value.
+ */
+func (r *Random) Int(start uint, max uint) uint {
+
+	if max <= start {
+		return start
+	}
+
+	return start + uint(r.Uint32()%uint32(max-start))
+}
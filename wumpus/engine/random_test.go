@@ -0,0 +1,54 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"testing"
+)
+
+func TestRandomRepeats(t *testing.T) {
+
+	for _, seed := range []uint32{0, 1, 0xDEADBEEF} {
+		first := NewRandom(seed)
+		second := NewRandom(seed)
+		for i := 0; i < 100; i++ {
+			if first.Uint32() != second.Uint32() {
+				t.Fatalf("seed 0x%08X: sequences differ at %d", seed, i)
+			}
+		}
+
+		if first.Seed() != seed {
+			t.Errorf("seed 0x%08X reported as 0x%08X", seed, first.Seed())
+		}
+	}
+}
+
+func TestRandomInt(t *testing.T) {
+
+	random := NewRandom(1234)
+	seen := [4]bool{}
+	for i := 0; i < 1000; i++ {
+		value := random.Int(3, 7)
+		if value < 3 || value >= 7 {
+			t.Fatalf("rolled %d, expected 3 to 6", value)
+		}
+
+		seen[value-3] = true
+	}
+
+	if seen != [4]bool{true, true, true, true} {
+		t.Errorf("not every value rolled: %v", seen)
+	}
+
+	// An empty range has only the one value
+	if value := random.Int(5, 5); value != 5 {
+		t.Errorf("rolled %d from an empty range", value)
+	}
+}
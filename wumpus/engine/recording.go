@@ -0,0 +1,349 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Recorded event types
+	EVENT_MOVE   uint8 = 'M'
+	EVENT_FIRE   uint8 = 'F'
+	EVENT_RANDOM uint8 = 'R'
+
+	// Recording text markers
+	RECORDING_HEADER string = "WUMPUS 1"
+	RECORDING_FOOTER string = "END"
+)
+
+/*
+ * A single recorded event: a joystick move, a press of the
+ * Fire button, or a roll made by the game
+ */
+type Event struct {
+	// Milliseconds since the game began
+	Time uint32
+	Kind uint8
+	// The direction moved or the value rolled
+	Value uint32
+}
+
+/*
+ * Everything needed to play a game back exactly
+ */
+type Recording struct {
+	Seed   uint32
+	Cave   string
+	Rules  Rules
+	Events []Event
+}
+
+/*
+ * A Source that passes on the rolls of another Source,
+ * and records them along with the player's input
+ */
+type Recorder struct {
+	Recording Recording
+	source    Source
+	now       uint32
+}
+
+/*
+ * A Source that plays back a recording's rolls, and
+ * hands its input events out as they fall due
+ */
+type Replay struct {
+	recording Recording
+	nextInput int
+	nextRoll  int
+	// Used if the recording runs out of rolls
+	fallback *Random
+}
+
+/*
+ * @brief Convenience method to instantiate a Recorder struct.
+ *
+ * @param seed:   The seed of the game to be recorded.
+ * @param cave:   The name of the game's cave layout.
+ * @param rules:  The game's rules.
+ * @param source: The source of the game's rolls.
+ */
+func NewRecorder(seed uint32, cave string, rules Rules, source Source) *Recorder {
+
+	return &Recorder{
+		Recording: Recording{Seed: seed, Cave: cave, Rules: rules},
+		source:    source,
+	}
+}
+
+/*
+ * @brief Roll a number and record the result.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: The value.
+ */
+func (r *Recorder) Int(start uint, max uint) uint {
+
+	value := r.source.Int(start, max)
+
+	// Rolls are stamped with the time of the input that caused them
+	r.Recording.Events = append(r.Recording.Events, Event{Time: r.now, Kind: EVENT_RANDOM, Value: uint32(value)})
+	return value
+}
+
+/*
+ * @brief Record a player input.
+ *
+ * @param time:  Milliseconds since the game began.
+ * @param kind:  `EVENT_MOVE` or `EVENT_FIRE`.
+ * @param value: The direction moved, if any.
+ */
+func (r *Recorder) Input(time uint32, kind uint8, value uint32) {
+
+	r.now = time
+	r.Recording.Events = append(r.Recording.Events, Event{Time: time, Kind: kind, Value: value})
+}
+
+/*
+ * @brief Convenience method to instantiate a Replay struct.
+ *
+ * @param recording: The game to play back.
+ */
+func NewReplay(recording Recording) *Replay {
+
+	return &Replay{
+		recording: recording,
+		fallback:  NewRandom(recording.Seed),
+	}
+}
+
+/*
+ * @brief Get the cave layout of the game being played back.
+ *
+ * @returns The layout.
+ */
+func (p *Replay) Topology() Topology {
+
+	topology, ok := TopologyNamed(p.recording.Cave)
+	if !ok {
+		return Grid{}
+	}
+
+	return topology
+}
+
+/*
+ * @brief Get the rules of the game being played back.
+ *
+ * @returns The rules.
+ */
+func (p *Replay) Rules() Rules {
+
+	return p.recording.Rules
+}
+
+/*
+ * @brief Get the seed of the game being played back.
+ *
+ * @returns The seed.
+ */
+func (p *Replay) Seed() uint32 {
+
+	return p.recording.Seed
+}
+
+/*
+ * @brief Get the next recorded player input, if it has fallen due.
+ *
+ * @param time: Milliseconds since the game began.
+ *
+ * @returns The event, and `true` if there was one to play.
+ */
+func (p *Replay) Next(time uint32) (Event, bool) {
+
+	for p.nextInput < len(p.recording.Events) {
+		event := p.recording.Events[p.nextInput]
+		if event.Kind == EVENT_RANDOM {
+			p.nextInput += 1
+			continue
+		}
+
+		if event.Time > time {
+			break
+		}
+
+		p.nextInput += 1
+		return event, true
+	}
+
+	return Event{}, false
+}
+
+/*
+ * @brief Have all of the recorded inputs been played?
+ *
+ * @returns `true` if the recording is finished, otherwise `false`.
+ */
+func (p *Replay) IsDone() bool {
+
+	for i := p.nextInput; i < len(p.recording.Events); i++ {
+		if p.recording.Events[i].Kind != EVENT_RANDOM {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+ * @brief Play back the next recorded roll.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: The value.
+ */
+func (p *Replay) Int(start uint, max uint) uint {
+
+	for p.nextRoll < len(p.recording.Events) {
+		event := p.recording.Events[p.nextRoll]
+		p.nextRoll += 1
+		if event.Kind == EVENT_RANDOM {
+			// The roll was recorded, so it's used up, but
+			// an empty range has only the one value
+			if max <= start {
+				return start
+			}
+
+			// Keep the roll in range even if the recording was edited
+			value := uint(event.Value)
+			if value < start || value >= max {
+				value = start + value%(max-start)
+			}
+
+			return value
+		}
+	}
+
+	return p.fallback.Int(start, max)
+}
+
+/*
+ * @brief Write out a recording as text, one event per line.
+ *
+ * @returns The recording.
+ */
+func (r *Recording) String() string {
+
+	var text strings.Builder
+	text.WriteString(RECORDING_HEADER + "\n")
+	seed := strings.ToUpper(strconv.FormatUint(uint64(r.Seed), 16))
+	text.WriteString("SEED " + strings.Repeat("0", 8-len(seed)) + seed + "\n")
+	if r.Cave != "" {
+		text.WriteString("CAVE " + r.Cave + "\n")
+	}
+
+	text.WriteString("RULES " + r.Rules.String() + "\n")
+
+	for _, event := range r.Events {
+		text.WriteByte(event.Kind)
+		text.WriteString(" " + strconv.FormatUint(uint64(event.Time), 10))
+		text.WriteString(" " + strconv.FormatUint(uint64(event.Value), 10) + "\n")
+	}
+
+	text.WriteString(RECORDING_FOOTER + "\n")
+	return text.String()
+}
+
+/*
+ * @brief Read a recording written by `Recording.String()`.
+ *
+ * @param text: The recording text.
+ *
+ * @returns The recording, and an error if the text could not be read.
+ */
+func ParseRecording(text string) (Recording, error) {
+
+	// Recordings made before rules were added were played by the standard rules
+	recording := Recording{Rules: NORMAL_RULES}
+	lines := strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	if len(lines) < 3 || strings.TrimSpace(lines[0]) != RECORDING_HEADER {
+		return recording, errors.New("not a Wumpus recording")
+	}
+
+	fields := strings.Fields(lines[1])
+	if len(fields) != 2 || fields[0] != "SEED" {
+		return recording, errors.New("recording has no seed")
+	}
+
+	seed, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return recording, err
+	}
+
+	recording.Seed = uint32(seed)
+	for _, line := range lines[2:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "CAVE ") {
+			recording.Cave = strings.TrimSpace(line[5:])
+			continue
+		}
+
+		if strings.HasPrefix(line, "RULES ") {
+			rules, err := ParseRules(line[6:])
+			if err != nil {
+				return recording, err
+			}
+
+			recording.Rules = rules
+			continue
+		}
+
+		if line == RECORDING_FOOTER {
+			return recording, nil
+		}
+
+		if line == "" {
+			continue
+		}
+
+		fields = strings.Fields(line)
+		if len(fields) != 3 || len(fields[0]) != 1 {
+			return recording, errors.New("bad recording line: " + line)
+		}
+
+		kind := fields[0][0]
+		if kind != EVENT_MOVE && kind != EVENT_FIRE && kind != EVENT_RANDOM {
+			return recording, errors.New("bad recording event: " + line)
+		}
+
+		eventTime, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return recording, err
+		}
+
+		value, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return recording, err
+		}
+
+		recording.Events = append(recording.Events, Event{Time: uint32(eventTime), Kind: kind, Value: uint32(value)})
+	}
+
+	return recording, errors.New("recording is incomplete")
+}
@@ -0,0 +1,166 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplayRollsBoard(t *testing.T) {
+
+	recorder := NewRecorder(1234, Dodecahedron{}.Name(), HARD_RULES, NewRandom(1234))
+	recorded := New(Dodecahedron{}, recorder)
+	recorded.Rules = HARD_RULES
+	recorded.Create()
+
+	recording, err := ParseRecording(recorder.Recording.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay := NewReplay(recording)
+	replayed := New(replay.Topology(), replay)
+	replayed.Rules = replay.Rules()
+	replayed.Create()
+
+	if replayed.PlayerRoom != recorded.PlayerRoom || !reflect.DeepEqual(replayed.Hazards, recorded.Hazards) {
+		t.Error("replayed board differs")
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+
+	recorder := NewRecorder(0xC0FFEE, Grid{}.Name(), EASY_RULES, NewRandom(1))
+	recorder.Int(0, 8)
+	recorder.Input(120, EVENT_MOVE, uint32(LEFT))
+	recorder.Int(0, 8)
+	recorder.Input(4500, EVENT_FIRE, 0)
+
+	recording, err := ParseRecording(recorder.Recording.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(recording, recorder.Recording) {
+		t.Errorf("read back %v, expected %v", recording, recorder.Recording)
+	}
+}
+
+func TestParseRecordingErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"no header", "SEED 00000001\nEND\n"},
+		{"no seed", "WUMPUS 1\nM 0 1\nEND\n"},
+		{"bad seed", "WUMPUS 1\nSEED XYZ\nEND\n"},
+		{"bad event", "WUMPUS 1\nSEED 00000001\nQ 0 1\nEND\n"},
+		{"bad time", "WUMPUS 1\nSEED 00000001\nM -1 1\nEND\n"},
+		{"short line", "WUMPUS 1\nSEED 00000001\nM 0\nEND\n"},
+		{"no footer", "WUMPUS 1\nSEED 00000001\nM 0 1\n"},
+		{"bad rules", "WUMPUS 1\nSEED 00000001\nRULES 1 2 3\nEND\n"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseRecording(test.text); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
+
+func TestParseRecordingWithoutRules(t *testing.T) {
+
+	// Recordings made before rules were added keep the standard rules
+	recording, err := ParseRecording("WUMPUS 1\nSEED 00000001\nCAVE GRID\nM 0 1\nEND\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recording.Rules != NORMAL_RULES {
+		t.Errorf("read rules %v, expected %v", recording.Rules, NORMAL_RULES)
+	}
+}
+
+func TestReplayNext(t *testing.T) {
+
+	replay := NewReplay(Recording{Events: []Event{
+		{Time: 0, Kind: EVENT_RANDOM, Value: 3},
+		{Time: 100, Kind: EVENT_MOVE, Value: uint32(UP)},
+		{Time: 100, Kind: EVENT_RANDOM, Value: 5},
+		{Time: 300, Kind: EVENT_FIRE},
+	}})
+
+	// Inputs are handed out as they fall due, skipping the rolls
+	if _, ok := replay.Next(50); ok {
+		t.Error("input played early")
+	}
+
+	if event, ok := replay.Next(100); !ok || event.Kind != EVENT_MOVE || event.Value != uint32(UP) {
+		t.Errorf("got %v, expected the move", event)
+	}
+
+	if replay.IsDone() {
+		t.Error("done with the Fire press still to play")
+	}
+
+	if event, ok := replay.Next(1000); !ok || event.Kind != EVENT_FIRE {
+		t.Errorf("got %v, expected the Fire press", event)
+	}
+
+	if !replay.IsDone() {
+		t.Error("not done after the last input")
+	}
+}
+
+func TestReplayInt(t *testing.T) {
+
+	replay := NewReplay(Recording{Seed: 99, Events: []Event{
+		{Kind: EVENT_RANDOM, Value: 3},
+		{Kind: EVENT_MOVE, Value: uint32(UP)},
+		{Kind: EVENT_RANDOM, Value: 9},
+	}})
+
+	if value := replay.Int(0, 4); value != 3 {
+		t.Errorf("got %d, expected 3", value)
+	}
+
+	// An edited roll is kept in range
+	if value := replay.Int(2, 6); value != 3 {
+		t.Errorf("got %d, expected 3", value)
+	}
+
+	// Once the rolls run out, they come from the seed
+	random := NewRandom(99)
+	for i := 0; i < 10; i++ {
+		if value, expected := replay.Int(0, 100), random.Int(0, 100); value != expected {
+			t.Fatalf("got %d, expected %d", value, expected)
+		}
+	}
+}
+
+func TestReplayIntEmptyRange(t *testing.T) {
+
+	replay := NewReplay(Recording{Events: []Event{
+		{Kind: EVENT_RANDOM, Value: 9},
+		{Kind: EVENT_RANDOM, Value: 5},
+	}})
+
+	// An empty range uses up the roll, without dividing by zero
+	if value := replay.Int(2, 2); value != 2 {
+		t.Errorf("got %d, expected 2", value)
+	}
+
+	if value := replay.Int(0, 8); value != 5 {
+		t.Errorf("got %d, expected 5", value)
+	}
+}
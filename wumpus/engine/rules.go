@@ -0,0 +1,187 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Limits on the rules a player may set
+	MAX_BATS   uint = 6
+	MAX_PITS   uint = 6
+	MAX_ARROWS uint = 9
+
+	// Bat flights are rolled in quarters
+	BAT_MOVE_ROLL uint = 4
+
+	// The number of values in a rules string
+	RULES_FIELDS int = 8
+)
+
+/*
+ * The settings that make a game easier or harder
+ */
+type Rules struct {
+	// Each board has between the minimum and maximum of each hazard
+	MinBats uint
+	MaxBats uint
+	MinPits uint
+	MaxPits uint
+
+	// Quiver size
+	Arrows uint
+
+	// Does the Wumpus roam once it's woken?
+	IsWumpusRoaming bool
+
+	// Do the senses felt in visited rooms stay on the map?
+	AreSensesKept bool
+
+	// The chance, in `BAT_MOVE_ROLL`, that a bat finds a new
+	// roost after it has carried the player off
+	BatMoveChance uint
+}
+
+/*
+ * GLOBALS
+ */
+var (
+	// Hazards are few and stay put
+	EASY_RULES Rules = Rules{
+		MinBats:         1,
+		MaxBats:         2,
+		MinPits:         1,
+		MaxPits:         2,
+		Arrows:          7,
+		IsWumpusRoaming: false,
+		AreSensesKept:   true,
+		BatMoveChance:   0,
+	}
+
+	// The standard game
+	NORMAL_RULES Rules = Rules{
+		MinBats:         1,
+		MaxBats:         3,
+		MinPits:         1,
+		MaxPits:         3,
+		Arrows:          ARROWS,
+		IsWumpusRoaming: true,
+		AreSensesKept:   false,
+		BatMoveChance:   0,
+	}
+
+	// More hazards, fewer arrows, and bats that never roost twice
+	HARD_RULES Rules = Rules{
+		MinBats:         2,
+		MaxBats:         4,
+		MinPits:         2,
+		MaxPits:         4,
+		Arrows:          3,
+		IsWumpusRoaming: true,
+		AreSensesKept:   false,
+		BatMoveChance:   BAT_MOVE_ROLL,
+	}
+)
+
+/*
+ * @brief Check that the rules can be played.
+ *
+ * @returns An error describing the first problem, or `nil`.
+ */
+func (r Rules) Check() error {
+
+	if r.MinBats > r.MaxBats || r.MaxBats > MAX_BATS {
+		return errors.New("bad bat count")
+	}
+
+	if r.MinPits > r.MaxPits || r.MaxPits > MAX_PITS {
+		return errors.New("bad pit count")
+	}
+
+	if r.Arrows == 0 || r.Arrows > MAX_ARROWS {
+		return errors.New("bad arrow count")
+	}
+
+	if r.BatMoveChance > BAT_MOVE_ROLL {
+		return errors.New("bad bat move chance")
+	}
+
+	return nil
+}
+
+/*
+ * @brief Write out the rules as text: their values in
+ *        order, separated by spaces.
+ *
+ * @returns The rules.
+ */
+func (r Rules) String() string {
+
+	values := []uint{r.MinBats, r.MaxBats, r.MinPits, r.MaxPits, r.Arrows, 0, 0, r.BatMoveChance}
+	if r.IsWumpusRoaming {
+		values[5] = 1
+	}
+
+	if r.AreSensesKept {
+		values[6] = 1
+	}
+
+	fields := make([]string, len(values))
+	for i, value := range values {
+		fields[i] = strconv.FormatUint(uint64(value), 10)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+/*
+ * @brief Read rules written by `Rules.String()`.
+ *
+ * @param text: The rules text.
+ *
+ * @returns The rules, and an error if the text could not be read
+ *          or the rules can't be played.
+ */
+func ParseRules(text string) (Rules, error) {
+
+	fields := strings.Fields(text)
+	if len(fields) != RULES_FIELDS {
+		return Rules{}, errors.New("bad rules: " + text)
+	}
+
+	values := make([]uint, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 8)
+		if err != nil {
+			return Rules{}, err
+		}
+
+		values[i] = uint(value)
+	}
+
+	rules := Rules{
+		MinBats:         values[0],
+		MaxBats:         values[1],
+		MinPits:         values[2],
+		MaxPits:         values[3],
+		Arrows:          values[4],
+		IsWumpusRoaming: values[5] != 0,
+		AreSensesKept:   values[6] != 0,
+		BatMoveChance:   values[7],
+	}
+
+	return rules, rules.Check()
+}
@@ -0,0 +1,93 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"testing"
+)
+
+func TestPresetsCanBePlayed(t *testing.T) {
+
+	for _, rules := range testRules {
+		if err := rules.Check(); err != nil {
+			t.Errorf("%s: %v", rules, err)
+		}
+	}
+
+	// Each preset is harder than the one before
+	if EASY_RULES.MaxPits >= HARD_RULES.MaxPits || EASY_RULES.Arrows <= HARD_RULES.Arrows || EASY_RULES.IsWumpusRoaming {
+		t.Error("easy rules aren't easier than hard rules")
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		rules   Rules
+		isValid bool
+	}{
+		{"fewest", Rules{Arrows: 1}, true},
+		{"most", Rules{MinBats: MAX_BATS, MaxBats: MAX_BATS, MinPits: MAX_PITS, MaxPits: MAX_PITS, Arrows: MAX_ARROWS, BatMoveChance: BAT_MOVE_ROLL}, true},
+		{"bats reversed", Rules{MinBats: 3, MaxBats: 2, Arrows: 1}, false},
+		{"too many bats", Rules{MaxBats: MAX_BATS + 1, Arrows: 1}, false},
+		{"pits reversed", Rules{MinPits: 3, MaxPits: 2, Arrows: 1}, false},
+		{"too many pits", Rules{MaxPits: MAX_PITS + 1, Arrows: 1}, false},
+		{"no arrows", Rules{Arrows: 0}, false},
+		{"too many arrows", Rules{Arrows: MAX_ARROWS + 1}, false},
+		{"bats too restless", Rules{Arrows: 1, BatMoveChance: BAT_MOVE_ROLL + 1}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.rules.Check(); (err == nil) != test.isValid {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+}
+
+func TestRulesRoundTrip(t *testing.T) {
+
+	custom := Rules{MinBats: 0, MaxBats: 5, MinPits: 2, MaxPits: 2, Arrows: 9, IsWumpusRoaming: true, AreSensesKept: true, BatMoveChance: 1}
+	for _, rules := range append(testRules, custom) {
+		parsed, err := ParseRules(rules.String())
+		if err != nil {
+			t.Errorf("%s: %v", rules, err)
+		}
+
+		if parsed != rules {
+			t.Errorf("read back %v, expected %v", parsed, rules)
+		}
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"too few", "1 3 1 3 5 1 0"},
+		{"too many", "1 3 1 3 5 1 0 0 0"},
+		{"not a number", "1 3 1 3 five 1 0 0"},
+		{"negative", "1 3 1 3 -5 1 0 0"},
+		{"too big", "1 3 1 3 256 1 0 0"},
+		{"bats reversed", "3 1 1 3 5 1 0 0"},
+		{"too many pits", "1 3 1 7 5 1 0 0"},
+		{"no arrows", "1 3 1 3 0 1 0 0"},
+		{"bats too restless", "1 3 1 3 5 1 0 5"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseRules(test.text); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
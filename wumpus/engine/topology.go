@@ -0,0 +1,202 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+/*
+ * The layout of the cave: how many rooms it has and how they
+ * connect. Rooms are numbered from zero
+ */
+type Topology interface {
+	// The layout's name, as used in recordings
+	Name() string
+	// The number of rooms in the cave
+	Rooms() int
+	// The room reached by leaving `room` in `direction`, if there is a way
+	Neighbour(room int, direction uint) (int, bool)
+	// All of the rooms reached directly from `room`
+	Neighbours(room int) []int
+	// The rooms the player may start in
+	StartRooms() []int
+}
+
+/*
+ * The original 8x8 cave: every square connects to the squares
+ * above, below and either side of it. Room numbers run up each
+ * column in turn, so room 0 is (0,0) and room 63 is (7,7)
+ */
+type Grid struct{}
+
+/*
+ * Gregory Yob's 1975 cave: 20 rooms at the vertices of a
+ * dodecahedron, each with three tunnels. The joystick picks a
+ * tunnel: left for the first, up for the second, right for the third
+ */
+type Dodecahedron struct{}
+
+/*
+ * The dodecahedron's tunnels, as in the original game
+ * but with the rooms numbered from zero
+ */
+var DODECAHEDRON_TUNNELS [20][3]int = [20][3]int{
+	{1, 4, 7}, {0, 2, 9}, {1, 3, 11}, {2, 4, 13}, {0, 3, 5},
+	{4, 6, 14}, {5, 7, 16}, {0, 6, 8}, {7, 9, 17}, {1, 8, 10},
+	{9, 11, 18}, {2, 10, 12}, {11, 13, 19}, {3, 12, 14}, {5, 13, 15},
+	{14, 16, 19}, {6, 15, 17}, {8, 16, 18}, {10, 17, 19}, {12, 15, 18},
+}
+
+/*
+ * @brief Find a layout by name.
+ *
+ * @param name: The layout's name.
+ *
+ * @returns The layout, and `false` if the name is unknown.
+ */
+func TopologyNamed(name string) (Topology, bool) {
+
+	switch name {
+	case Grid{}.Name():
+		return Grid{}, true
+	case Dodecahedron{}.Name():
+		return Dodecahedron{}, true
+	}
+
+	return nil, false
+}
+
+/*
+ * @brief Get the room at a grid square.
+ *
+ * @param x: The square's X co-ordinate.
+ * @param y: The square's Y co-ordinate.
+ *
+ * @returns The room number.
+ */
+func GridRoom(x uint, y uint) int {
+
+	return int(x*8 + y)
+}
+
+/*
+ * @brief Get the grid square of a room.
+ *
+ * @param room: The room number.
+ *
+ * @returns The square's X and Y co-ordinates.
+ */
+func GridPosition(room int) (uint, uint) {
+
+	return uint(room / 8), uint(room % 8)
+}
+
+func (g Grid) Name() string {
+
+	return "GRID"
+}
+
+func (g Grid) Rooms() int {
+
+	return 64
+}
+
+func (g Grid) Neighbour(room int, direction uint) (int, bool) {
+
+	x, y := GridPosition(room)
+	switch direction {
+	case UP:
+		if y < 7 {
+			return GridRoom(x, y+1), true
+		}
+	case DOWN:
+		if y > 0 {
+			return GridRoom(x, y-1), true
+		}
+	case LEFT:
+		if x > 0 {
+			return GridRoom(x-1, y), true
+		}
+	case RIGHT:
+		if x < 7 {
+			return GridRoom(x+1, y), true
+		}
+	}
+
+	return room, false
+}
+
+func (g Grid) Neighbours(room int) []int {
+
+	return neighbours(g, room)
+}
+
+func (g Grid) StartRooms() []int {
+
+	// The four corners
+	return []int{GridRoom(0, 0), GridRoom(0, 7), GridRoom(7, 7), GridRoom(7, 0)}
+}
+
+func (d Dodecahedron) Name() string {
+
+	return "DODECAHEDRON"
+}
+
+func (d Dodecahedron) Rooms() int {
+
+	return 20
+}
+
+func (d Dodecahedron) Neighbour(room int, direction uint) (int, bool) {
+
+	switch direction {
+	case LEFT:
+		return DODECAHEDRON_TUNNELS[room][0], true
+	case UP:
+		return DODECAHEDRON_TUNNELS[room][1], true
+	case RIGHT:
+		return DODECAHEDRON_TUNNELS[room][2], true
+	}
+
+	return room, false
+}
+
+func (d Dodecahedron) Neighbours(room int) []int {
+
+	return DODECAHEDRON_TUNNELS[room][:]
+}
+
+func (d Dodecahedron) StartRooms() []int {
+
+	// Any room will do
+	rooms := make([]int, 20)
+	for i := 0; i < 20; i++ {
+		rooms[i] = i
+	}
+
+	return rooms
+}
+
+/*
+ * @brief Find a room's neighbours by trying every direction.
+ *
+ * @param topology: The cave layout.
+ * @param room:     The room number.
+ *
+ * @returns The neighbouring rooms.
+ */
+func neighbours(topology Topology, room int) []int {
+
+	rooms := []int{}
+	for _, direction := range []uint{UP, DOWN, LEFT, RIGHT} {
+		if next, ok := topology.Neighbour(room, direction); ok {
+			rooms = append(rooms, next)
+		}
+	}
+
+	return rooms
+}
@@ -0,0 +1,133 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"testing"
+)
+
+/*
+ * @brief Count the rooms that can be reached from room 0.
+ *
+ * @param topology: The cave layout.
+ *
+ * @returns The number of rooms, including room 0.
+ */
+func countReachable(topology Topology) int {
+
+	isReached := make([]bool, topology.Rooms())
+	isReached[0] = true
+	queue := []int{0}
+	count := 1
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		for _, next := range topology.Neighbours(room) {
+			if !isReached[next] {
+				isReached[next] = true
+				queue = append(queue, next)
+				count += 1
+			}
+		}
+	}
+
+	return count
+}
+
+func TestTopologiesAreConnected(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		if count := countReachable(topology); count != topology.Rooms() {
+			t.Errorf("%s: %d of %d rooms reachable", topology.Name(), count, topology.Rooms())
+		}
+	}
+}
+
+func TestTunnelsGoBothWays(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		for room := 0; room < topology.Rooms(); room++ {
+			for _, next := range topology.Neighbours(room) {
+				isLinked := false
+				for _, back := range topology.Neighbours(next) {
+					isLinked = isLinked || back == room
+				}
+
+				if !isLinked {
+					t.Errorf("%s: room %d leads to %d, but not back", topology.Name(), room, next)
+				}
+			}
+		}
+	}
+}
+
+func TestDodecahedronRooms(t *testing.T) {
+
+	cave := Dodecahedron{}
+	if cave.Rooms() != 20 {
+		t.Fatalf("%d rooms, expected 20", cave.Rooms())
+	}
+
+	for room := 0; room < cave.Rooms(); room++ {
+		tunnels := cave.Neighbours(room)
+		if len(tunnels) != 3 {
+			t.Errorf("room %d has %d tunnels", room, len(tunnels))
+			continue
+		}
+
+		if tunnels[0] == tunnels[1] || tunnels[1] == tunnels[2] || tunnels[0] == tunnels[2] {
+			t.Errorf("room %d has two tunnels to one room: %v", room, tunnels)
+		}
+
+		for _, next := range tunnels {
+			if next == room || next < 0 || next >= cave.Rooms() {
+				t.Errorf("room %d has a tunnel to room %d", room, next)
+			}
+		}
+	}
+}
+
+func TestGridNeighbours(t *testing.T) {
+
+	tests := []struct {
+		x, y  uint
+		count int
+	}{
+		{0, 0, 2},
+		{7, 7, 2},
+		{0, 4, 3},
+		{4, 7, 3},
+		{3, 3, 4},
+	}
+
+	for _, test := range tests {
+		room := GridRoom(test.x, test.y)
+		if x, y := GridPosition(room); x != test.x || y != test.y {
+			t.Errorf("room %d is at %d,%d, expected %d,%d", room, x, y, test.x, test.y)
+		}
+
+		if count := len(Grid{}.Neighbours(room)); count != test.count {
+			t.Errorf("%d,%d has %d neighbours, expected %d", test.x, test.y, count, test.count)
+		}
+	}
+}
+
+func TestTopologyNamed(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		if named, ok := TopologyNamed(topology.Name()); !ok || named != topology {
+			t.Errorf("%s not found by name", topology.Name())
+		}
+	}
+
+	if _, ok := TopologyNamed("TORUS"); ok {
+		t.Error("found an unknown layout")
+	}
+}
@@ -0,0 +1,632 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+/*
+ * The complete game state. All of the rules operate on this,
+ * and report what happened rather than presenting it: that's
+ * the job of the front end
+ */
+type World struct {
+	// The cave's layout
+	Topology Topology
+
+	// Game board maps, indexed by room number
+	Hazards      []uint8
+	Visited      []bool
+	StinkLayer   []bool
+	SoundLayer   []bool
+	DraughtLayer []bool
+
+	// What the player sensed in each room they visited, as it
+	// was when they left: hazards may have moved since
+	Felt []uint8
+
+	// Player state
+	PlayerRoom        int
+	LastMoveDirection uint
+	IsInPlay          bool
+
+	// The rules in force. Set these before calling `Create()`
+	Rules Rules
+
+	// Arrow state: how far an arrow flies, the number left,
+	// and the rooms the last arrow flew through
+	ArrowRange  uint
+	Arrows      uint
+	ArrowFlight []int
+
+	// Wumpus state
+	WumpusRoom    int
+	IsWumpusAwake bool
+
+	// Random number source
+	random Source
+}
+
+/*
+ * @brief Convenience method to instantiate a World struct.
+ *        Call `Create()` to roll a board before play.
+ *
+ * @param topology: The cave's layout, eg. `Grid{}`.
+ * @param random:   The source of all of the game's rolls. Pass a `Random`
+ *                  created with a known seed to replay a board.
+ */
+func New(topology Topology, random Source) World {
+
+	rooms := topology.Rooms()
+	return World{
+		Topology:          topology,
+		Hazards:           make([]uint8, rooms),
+		Visited:           make([]bool, rooms),
+		StinkLayer:        make([]bool, rooms),
+		SoundLayer:        make([]bool, rooms),
+		DraughtLayer:      make([]bool, rooms),
+		Felt:              make([]uint8, rooms),
+		random:            random,
+		LastMoveDirection: UP,
+		Rules:             NORMAL_RULES,
+		ArrowRange:        ARROW_RANGE,
+	}
+}
+
+/*
+ * @brief Roll a new board.
+ */
+func (w *World) Create() {
+
+	// Pick the player's start point
+	startRooms := w.Topology.StartRooms()
+	w.PlayerRoom = startRooms[w.random.Int(0, uint(len(startRooms)))]
+
+	// Set the incoming direction: the first way into the cave
+	w.LastMoveDirection = NONE
+	for _, direction := range []uint{UP, DOWN, LEFT, RIGHT} {
+		if _, ok := w.Topology.Neighbour(w.PlayerRoom, direction); ok {
+			w.LastMoveDirection = direction
+			break
+		}
+	}
+
+	// The Wumpus sleeps until disturbed
+	w.IsWumpusAwake = false
+
+	// Fill the quiver
+	w.Arrows = w.Rules.Arrows
+	w.ArrowFlight = nil
+
+	// Roll boards until one can be won
+	isWinnable := false
+	for i := 0; i < MAX_BOARD_ROLLS && !isWinnable; i++ {
+		w.clear()
+
+		// Create the bats
+		isPlaced := w.rollHazards(BAT, w.random.Int(w.Rules.MinBats, w.Rules.MaxBats+1))
+
+		// Create the pits
+		isPlaced = isPlaced && w.rollHazards(PIT, w.random.Int(w.Rules.MinPits, w.Rules.MaxPits+1))
+
+		// Create one wumpus
+		// NOTE It's generated last so bats and pits
+		//      can't overwrite it by chance
+		isPlaced = isPlaced && w.rollHazards(WUMPUS, 1)
+
+		isWinnable = isPlaced && w.IsWinnable()
+	}
+
+	if !isWinnable {
+		// Fall back to a board with only the Wumpus,
+		// as far from the player as it can be
+		w.clear()
+		distances := w.distancesFrom([]int{w.PlayerRoom}, false)
+		farthest := w.PlayerRoom
+		for room, distance := range distances {
+			if distance > distances[farthest] {
+				farthest = room
+			}
+		}
+
+		w.Hazards[farthest] = WUMPUS
+	}
+
+	// Generate sense data for sounds and LED reactions
+	for room, hazard := range w.Hazards {
+		switch hazard {
+		case WUMPUS:
+			w.WumpusRoom = room
+			w.markAround(w.StinkLayer, room)
+		case PIT:
+			w.markAround(w.DraughtLayer, room)
+		case BAT:
+			w.markAround(w.SoundLayer, room)
+		}
+	}
+
+	w.IsInPlay = true
+}
+
+/*
+ * @brief Check that the board is fair: the player's start room and
+ *        the rooms next to it are free of hazards, and the player can
+ *        reach or shoot the Wumpus without passing a pit or a bat.
+ *
+ * @returns `true` if the board can be won, otherwise `false`.
+ */
+func (w *World) IsWinnable() bool {
+
+	if w.Hazards[w.PlayerRoom] != EMPTY {
+		return false
+	}
+
+	for _, next := range w.Topology.Neighbours(w.PlayerRoom) {
+		if w.Hazards[next] != EMPTY {
+			return false
+		}
+	}
+
+	// Find every room the player can walk to safely...
+	reachable := []int{}
+	for room, distance := range w.distancesFrom([]int{w.PlayerRoom}, true) {
+		if distance >= 0 && w.Hazards[room] == EMPTY {
+			reachable = append(reachable, room)
+		}
+	}
+
+	// ...then how far the Wumpus is from the nearest of them. Next door
+	// means it can be reached; within arrow range means it can be shot
+	distance := w.distancesFrom(reachable, false)[w.WumpusRoom]
+	if distance < 0 {
+		return false
+	}
+
+	return distance == 1 || uint(distance) <= w.ArrowRange
+}
+
+/*
+ * @brief Move the player to the next room, if the cave allows it,
+ *        then check the new location for hazards.
+ *
+ * @param direction: The direction of movement.
+ *
+ * @returns Whether the player changed room, and what they found there.
+ */
+func (w *World) Move(direction uint) (bool, Outcome) {
+
+	// Record the player's current location, and what
+	// they sensed there, before the move
+	w.Visited[w.PlayerRoom] = true
+	w.Felt[w.PlayerRoom] = w.senseFlags(w.PlayerRoom)
+
+	next, moved := w.Topology.Neighbour(w.PlayerRoom, direction)
+	if moved {
+		w.PlayerRoom = next
+		w.LastMoveDirection = direction
+	}
+
+	// Check the new location for hazards
+	return moved, w.checkHazards()
+}
+
+/*
+ * @brief Get the path of an arrow shot in a straight line.
+ *
+ * @param direction: The direction to shoot, eg. `LastMoveDirection`.
+ *
+ * @returns The path, as a direction for each room.
+ */
+func (w *World) StraightPath(direction uint) []uint {
+
+	path := make([]uint, w.ArrowRange)
+	for i := range path {
+		path[i] = direction
+	}
+
+	return path
+}
+
+/*
+ * @brief Fire an arrow along a path, which may be crooked: the arrow
+ *        leaves each room in the next direction given, until it hits
+ *        a wall or has flown `ArrowRange` rooms. A miss wakes the Wumpus.
+ *
+ * @param path: The direction to take out of each room in turn.
+ *
+ * @returns `KILLED_WUMPUS`, `SHOT_SELF`, `MISSED_WUMPUS` or
+ *          `OUT_OF_ARROWS`, or `SAFE` if the player is facing a wall
+ *          or has no arrows.
+ */
+func (w *World) Fire(path []uint) Outcome {
+
+	if w.Arrows == 0 || len(path) == 0 {
+		return SAFE
+	}
+
+	if _, ok := w.Topology.Neighbour(w.PlayerRoom, path[0]); !ok {
+		return SAFE
+	}
+
+	if uint(len(path)) > w.ArrowRange {
+		path = path[:w.ArrowRange]
+	}
+
+	w.Arrows -= 1
+	w.ArrowFlight = w.ArrowFlight[:0]
+	room := w.PlayerRoom
+	for _, direction := range path {
+		next, ok := w.Topology.Neighbour(room, direction)
+		if !ok {
+			// The arrow hit a wall
+			break
+		}
+
+		room = next
+		w.ArrowFlight = append(w.ArrowFlight, room)
+		if room == w.WumpusRoom {
+			w.IsInPlay = false
+			return KILLED_WUMPUS
+		}
+
+		if room == w.PlayerRoom {
+			w.IsInPlay = false
+			return SHOT_SELF
+		}
+	}
+
+	w.IsWumpusAwake = true
+	if w.Arrows == 0 {
+		w.IsInPlay = false
+		return OUT_OF_ARROWS
+	}
+
+	return MISSED_WUMPUS
+}
+
+/*
+ * @brief Let the Wumpus act. Once awake, it usually moves to a
+ *        neighbouring room, and eats the player if it finds them.
+ *        Call this after each of the player's moves and shots.
+ *
+ * @returns `WUMPUS_MOVED`, `EATEN_BY_WUMPUS`, or `SAFE` if the
+ *          Wumpus stayed put.
+ */
+func (w *World) WumpusTurn() Outcome {
+
+	if !w.IsInPlay || !w.IsWumpusAwake || !w.Rules.IsWumpusRoaming {
+		return SAFE
+	}
+
+	if w.random.Int(0, WUMPUS_MOVE_ROLL) >= WUMPUS_MOVE_CHANCE {
+		return SAFE
+	}
+
+	// The Wumpus can't share a room with a bat or a pit
+	rooms := []int{}
+	for _, next := range w.Topology.Neighbours(w.WumpusRoom) {
+		if w.Hazards[next] == EMPTY {
+			rooms = append(rooms, next)
+		}
+	}
+
+	if len(rooms) == 0 {
+		return SAFE
+	}
+
+	w.Hazards[w.WumpusRoom] = EMPTY
+	w.WumpusRoom = rooms[w.random.Int(0, uint(len(rooms)))]
+	w.Hazards[w.WumpusRoom] = WUMPUS
+
+	// Move the smell with it
+	for i := range w.StinkLayer {
+		w.StinkLayer[i] = false
+	}
+
+	w.markAround(w.StinkLayer, w.WumpusRoom)
+
+	if w.WumpusRoom == w.PlayerRoom {
+		w.IsInPlay = false
+		return EATEN_BY_WUMPUS
+	}
+
+	return WUMPUS_MOVED
+}
+
+/*
+ * @brief Get the sense information for the player's location.
+ *
+ * @returns Whether the player can smell the Wumpus, feel a pit's
+ *          draught and hear a bat.
+ */
+func (w *World) Senses() (bool, bool, bool) {
+
+	return w.SensesIn(w.PlayerRoom)
+}
+
+/*
+ * @brief Get the sense information for any room.
+ *
+ * @param room: The room number.
+ *
+ * @returns Whether the Wumpus can be smelled, a pit's draught
+ *          felt and a bat heard in the room.
+ */
+func (w *World) SensesIn(room int) (bool, bool, bool) {
+
+	return w.StinkLayer[room],
+		w.DraughtLayer[room],
+		w.SoundLayer[room]
+}
+
+/*
+ * @brief Get what the player sensed in a room when they were last
+ *        there. Unlike `SensesIn()`, this doesn't change as the
+ *        Wumpus and the bats move.
+ *
+ * @param room: The room number.
+ *
+ * @returns Whether the player smelled the Wumpus, felt a pit's
+ *          draught and heard a bat in the room, or all `false`
+ *          if they haven't visited it.
+ */
+func (w *World) FeltIn(room int) (bool, bool, bool) {
+
+	felt := w.Felt[room]
+	return felt&FELT_STINK != 0,
+		felt&FELT_DRAUGHT != 0,
+		felt&FELT_SOUND != 0
+}
+
+/*
+ * @brief Locate a hazard in the cave, away from the player.
+ *
+ * @param hazardType: The hazard to place.
+ * @param count:      The number to place.
+ *
+ * @returns `true` if every hazard was placed, or `false` if the
+ *          cave ran out of rooms for them.
+ */
+func (w *World) rollHazards(hazardType uint8, count uint) bool {
+
+	var i uint
+	for i = 0; i < count; i++ {
+		// Make sure the rolled room is empty and not next to the player
+		room, ok := w.rollRoom(func(room int) bool {
+			return w.Hazards[room] == EMPTY && room != w.PlayerRoom && !w.isNextTo(room, w.PlayerRoom)
+		})
+
+		if !ok {
+			return false
+		}
+
+		// Place the hazard
+		w.Hazards[room] = hazardType
+		if hazardType == WUMPUS {
+			w.WumpusRoom = room
+		}
+	}
+
+	return true
+}
+
+/*
+ * @brief Roll a room that suits the caller. If the rolls keep
+ *        missing, step on through the cave from the last one, so
+ *        a crowded cave can't leave the game rolling for ever.
+ *
+ * @param isFree: Does a room suit?
+ *
+ * @returns The room, and `false` if no room suits.
+ */
+func (w *World) rollRoom(isFree func(room int) bool) (int, bool) {
+
+	rooms := w.Topology.Rooms()
+	room := 0
+	for i := 0; i < MAX_ROOM_ROLLS; i++ {
+		room = int(w.random.Int(0, uint(rooms)))
+		if isFree(room) {
+			return room, true
+		}
+	}
+
+	for i := 1; i < rooms; i++ {
+		if next := (room + i) % rooms; isFree(next) {
+			return next, true
+		}
+	}
+
+	return room, false
+}
+
+/*
+ * @brief Has the player stepped on a hazard?
+ *        If they met a bat, they are dropped elsewhere.
+ *
+ * @returns What the player found.
+ */
+func (w *World) checkHazards() Outcome {
+
+	switch w.Hazards[w.PlayerRoom] {
+	case BAT:
+		// Player encountered a bat: drop them at random
+		room, ok := w.rollRoom(func(room int) bool {
+			return w.Hazards[room] == EMPTY
+		})
+
+		if !ok {
+			// Nowhere to drop them, so the bat lets go
+			return SAFE
+		}
+
+		// The bat may roost elsewhere
+		if w.Rules.BatMoveChance > 0 && w.random.Int(0, BAT_MOVE_ROLL) < w.Rules.BatMoveChance {
+			w.moveBat(w.PlayerRoom, room)
+		}
+
+		w.PlayerRoom = room
+
+		// The commotion may wake the Wumpus
+		if w.random.Int(0, BAT_WAKE_ROLL) < BAT_WAKE_CHANCE {
+			w.IsWumpusAwake = true
+		}
+
+		return GRABBED_BY_BAT
+	case PIT:
+		// Player fell down a pit -> death
+		w.IsInPlay = false
+		return FELL_INTO_PIT
+	case WUMPUS:
+		// Player ran into the Wumpus -> death
+		w.IsInPlay = false
+		return EATEN_BY_WUMPUS
+	}
+
+	// Player is safe... for now!
+	return SAFE
+}
+
+/*
+ * @brief Move a bat to a new roost, away from where it dropped the player.
+ *
+ * @param from:   The bat's room.
+ * @param player: The room the player was dropped in.
+ */
+func (w *World) moveBat(from int, player int) {
+
+	// The bat's own room always suits, so a roost is found
+	room, _ := w.rollRoom(func(room int) bool {
+		return room != player && (room == from || w.Hazards[room] == EMPTY)
+	})
+
+	w.Hazards[from] = EMPTY
+	w.Hazards[room] = BAT
+
+	// Move the sound with it
+	for i := range w.SoundLayer {
+		w.SoundLayer[i] = false
+	}
+
+	for i, hazard := range w.Hazards {
+		if hazard == BAT {
+			w.markAround(w.SoundLayer, i)
+		}
+	}
+}
+
+/*
+ * @brief Empty the cave and forget what the player has seen.
+ */
+func (w *World) clear() {
+
+	for i := range w.Hazards {
+		w.Hazards[i] = EMPTY
+		w.Visited[i] = false
+		w.Felt[i] = 0
+		w.StinkLayer[i] = false
+		w.DraughtLayer[i] = false
+		w.SoundLayer[i] = false
+	}
+}
+
+/*
+ * @brief Find how many moves it takes to reach each room
+ *        from the nearest of a set of rooms.
+ *
+ * @param rooms:    The rooms to start from.
+ * @param isWalked: `true` to pass only through rooms without hazards,
+ *                  `false` to pass through any room, as an arrow does.
+ *
+ * @returns The distance to each room, or -1 if it can't be reached.
+ */
+func (w *World) distancesFrom(rooms []int, isWalked bool) []int {
+
+	distances := make([]int, w.Topology.Rooms())
+	for i := range distances {
+		distances[i] = -1
+	}
+
+	queue := []int{}
+	for _, room := range rooms {
+		distances[room] = 0
+		queue = append(queue, room)
+	}
+
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		for _, next := range w.Topology.Neighbours(room) {
+			if distances[next] >= 0 {
+				continue
+			}
+
+			distances[next] = distances[room] + 1
+			if !isWalked || w.Hazards[next] == EMPTY {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return distances
+}
+
+/*
+ * @brief Are two rooms directly connected?
+ *
+ * @param room:  The first room.
+ * @param other: The second room.
+ *
+ * @returns `true` if they are neighbours, otherwise `false`.
+ */
+func (w *World) isNextTo(room int, other int) bool {
+
+	for _, next := range w.Topology.Neighbours(room) {
+		if next == other {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * @brief Get the sense information for a room as flags.
+ *
+ * @param room: The room number.
+ *
+ * @returns The senses, eg. `FELT_STINK | FELT_SOUND`.
+ */
+func (w *World) senseFlags(room int) uint8 {
+
+	felt := uint8(0)
+	if w.StinkLayer[room] {
+		felt |= FELT_STINK
+	}
+
+	if w.DraughtLayer[room] {
+		felt |= FELT_DRAUGHT
+	}
+
+	if w.SoundLayer[room] {
+		felt |= FELT_SOUND
+	}
+
+	return felt
+}
+
+/*
+ * @brief Flag the rooms around a hazard in a sense layer.
+ *
+ * @param layer: The sense layer to update.
+ * @param room:  The hazard's room.
+ */
+func (w *World) markAround(layer []bool, room int) {
+
+	for _, next := range w.Topology.Neighbours(room) {
+		layer[next] = true
+	}
+}
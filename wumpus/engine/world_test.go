@@ -0,0 +1,514 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	// Every cave layout and rules preset the game offers
+	testTopologies []Topology = []Topology{Grid{}, Dodecahedron{}}
+	testRules      []Rules    = []Rules{EASY_RULES, NORMAL_RULES, HARD_RULES}
+)
+
+/*
+ * A source whose every roll is the lowest it can be
+ */
+type stuckSource struct{}
+
+/*
+ * @brief Roll the bottom of the range.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: `start`.
+ */
+func (s stuckSource) Int(start uint, max uint) uint {
+
+	return start
+}
+
+/*
+ * @brief Make an empty grid cave with the player in the bottom left
+ *        corner and the Wumpus in the top right.
+ *
+ * @returns The world, ready to play.
+ */
+func newTestWorld() World {
+
+	w := New(Grid{}, NewRandom(1))
+	for room := range w.Hazards {
+		w.Hazards[room] = EMPTY
+	}
+
+	w.PlayerRoom = GridRoom(0, 0)
+	w.WumpusRoom = GridRoom(7, 7)
+	w.Hazards[w.WumpusRoom] = WUMPUS
+	w.Arrows = ARROWS
+	w.IsInPlay = true
+	return w
+}
+
+/*
+ * @brief Count the rooms that hold a hazard.
+ *
+ * @param w:      The world.
+ * @param hazard: The hazard to count.
+ *
+ * @returns The number of rooms.
+ */
+func countHazards(w *World, hazard uint8) uint {
+
+	count := uint(0)
+	for _, value := range w.Hazards {
+		if value == hazard {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+/*
+ * @brief Is a hazard in a room next to the given one?
+ *
+ * @param w:      The world.
+ * @param hazard: The hazard.
+ * @param room:   The room.
+ *
+ * @returns `true` if it is, otherwise `false`.
+ */
+func isHazardNextTo(w *World, hazard uint8, room int) bool {
+
+	for _, next := range w.Topology.Neighbours(room) {
+		if w.Hazards[next] == hazard {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCreate(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		for _, rules := range testRules {
+			for seed := uint32(0); seed < 200; seed++ {
+				w := New(topology, NewRandom(seed))
+				w.Rules = rules
+				w.Create()
+
+				if count := countHazards(&w, WUMPUS); count != 1 {
+					t.Fatalf("%s %s seed %d: %d Wumpuses", topology.Name(), rules, seed, count)
+				}
+
+				if w.Hazards[w.WumpusRoom] != WUMPUS || w.IsWumpusAwake {
+					t.Fatalf("%s %s seed %d: no sleeping Wumpus in room %d", topology.Name(), rules, seed, w.WumpusRoom)
+				}
+
+				if w.Arrows != rules.Arrows {
+					t.Fatalf("%s %s seed %d: %d arrows, expected %d", topology.Name(), rules, seed, w.Arrows, rules.Arrows)
+				}
+
+				if !w.IsWinnable() {
+					t.Fatalf("%s %s seed %d: board can't be won", topology.Name(), rules, seed)
+				}
+
+				// The fallback board has no bats or pits
+				bats := countHazards(&w, BAT)
+				pits := countHazards(&w, PIT)
+				isFallback := bats == 0 && pits == 0
+				if !isFallback && (bats < rules.MinBats || bats > rules.MaxBats || pits < rules.MinPits || pits > rules.MaxPits) {
+					t.Fatalf("%s %s seed %d: %d bats and %d pits", topology.Name(), rules, seed, bats, pits)
+				}
+
+				// Each sense is felt next to its hazard
+				for room := range w.Hazards {
+					if w.StinkLayer[room] != isHazardNextTo(&w, WUMPUS, room) ||
+						w.DraughtLayer[room] != isHazardNextTo(&w, PIT, room) ||
+						w.SoundLayer[room] != isHazardNextTo(&w, BAT, room) {
+						t.Fatalf("%s %s seed %d: senses wrong in room %d", topology.Name(), rules, seed, room)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCreateIsDeterministic(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		for seed := uint32(0); seed < 50; seed++ {
+			first := New(topology, NewRandom(seed))
+			first.Create()
+			second := New(topology, NewRandom(seed))
+			second.Create()
+
+			if first.PlayerRoom != second.PlayerRoom || !reflect.DeepEqual(first.Hazards, second.Hazards) {
+				t.Fatalf("%s seed %d: boards differ", topology.Name(), seed)
+			}
+		}
+	}
+}
+
+func TestCreateWithStuckRolls(t *testing.T) {
+
+	// Rolls that always miss step through the cave instead
+	for _, topology := range testTopologies {
+		for _, rules := range testRules {
+			w := New(topology, stuckSource{})
+			w.Rules = rules
+			w.Create()
+
+			if countHazards(&w, WUMPUS) != 1 || !w.IsWinnable() {
+				t.Errorf("%s %s: board can't be won", topology.Name(), rules)
+			}
+		}
+	}
+}
+
+func TestRollHazardsInFullCave(t *testing.T) {
+
+	w := newTestWorld()
+	for room := range w.Hazards {
+		if room != w.PlayerRoom && !w.isNextTo(room, w.PlayerRoom) && w.Hazards[room] == EMPTY {
+			w.Hazards[room] = PIT
+		}
+	}
+
+	// Only the rooms around the player are left, and they don't suit
+	if w.rollHazards(BAT, 1) {
+		t.Error("bat placed in a full cave")
+	}
+
+	if count := countHazards(&w, BAT); count != 0 {
+		t.Errorf("%d bats placed, expected none", count)
+	}
+}
+
+func TestIsWinnable(t *testing.T) {
+
+	// Rooms 16 to 23 are column 2, so pits there wall off the first two columns
+	tests := []struct {
+		name       string
+		pits       []int
+		wumpus     int
+		isWinnable bool
+	}{
+		{"open", nil, GridRoom(7, 7), true},
+		{"pit next to start", []int{GridRoom(0, 1)}, GridRoom(7, 7), false},
+		{"walled off", []int{16, 17, 18, 19, 20, 21, 22, 23}, GridRoom(7, 7), false},
+		{"shot over a wall", []int{16, 17, 18, 19, 20, 21, 22, 23}, GridRoom(5, 0), true},
+	}
+
+	for _, test := range tests {
+		w := newTestWorld()
+		w.Hazards[w.WumpusRoom] = EMPTY
+		w.WumpusRoom = test.wumpus
+		w.Hazards[w.WumpusRoom] = WUMPUS
+		for _, room := range test.pits {
+			w.Hazards[room] = PIT
+		}
+
+		if w.IsWinnable() != test.isWinnable {
+			t.Errorf("%s: winnable %t, expected %t", test.name, !test.isWinnable, test.isWinnable)
+		}
+	}
+}
+
+func TestWumpusTurnKeepsOneWumpus(t *testing.T) {
+
+	for _, topology := range testTopologies {
+		for seed := uint32(0); seed < 20; seed++ {
+			w := New(topology, NewRandom(seed))
+			w.Create()
+			w.IsWumpusAwake = true
+
+			for turn := 0; turn < 100 && w.IsInPlay; turn++ {
+				outcome := w.WumpusTurn()
+				if count := countHazards(&w, WUMPUS); count != 1 {
+					t.Fatalf("%s seed %d turn %d: %d Wumpuses", topology.Name(), seed, turn, count)
+				}
+
+				if w.Hazards[w.WumpusRoom] != WUMPUS {
+					t.Fatalf("%s seed %d turn %d: Wumpus not in room %d", topology.Name(), seed, turn, w.WumpusRoom)
+				}
+
+				// The smell follows the Wumpus
+				for room := range w.StinkLayer {
+					if w.StinkLayer[room] != isHazardNextTo(&w, WUMPUS, room) {
+						t.Fatalf("%s seed %d turn %d: stink wrong in room %d", topology.Name(), seed, turn, room)
+					}
+				}
+
+				if outcome == EATEN_BY_WUMPUS && w.WumpusRoom != w.PlayerRoom {
+					t.Fatalf("%s seed %d turn %d: eaten from another room", topology.Name(), seed, turn)
+				}
+			}
+		}
+	}
+}
+
+func TestWumpusSleepsUntilWoken(t *testing.T) {
+
+	w := newTestWorld()
+	for i := 0; i < 20; i++ {
+		if outcome := w.WumpusTurn(); outcome != SAFE || w.WumpusRoom != GridRoom(7, 7) {
+			t.Fatalf("sleeping Wumpus acted: %d", outcome)
+		}
+	}
+
+	// A miss wakes it
+	w.Fire([]uint{RIGHT})
+	for i := 0; i < 20 && w.WumpusRoom == GridRoom(7, 7); i++ {
+		w.WumpusTurn()
+	}
+
+	if w.WumpusRoom == GridRoom(7, 7) {
+		t.Error("woken Wumpus never moved")
+	}
+}
+
+func TestWumpusStaysPutUnlessRoaming(t *testing.T) {
+
+	w := newTestWorld()
+	w.Rules = EASY_RULES
+	w.IsWumpusAwake = true
+	for i := 0; i < 20; i++ {
+		if outcome := w.WumpusTurn(); outcome != SAFE || w.WumpusRoom != GridRoom(7, 7) {
+			t.Fatalf("Wumpus roamed: %d", outcome)
+		}
+	}
+}
+
+func TestBatsMove(t *testing.T) {
+
+	moves := 0
+	for seed := uint32(0); seed < 50; seed++ {
+		w := newTestWorld()
+		w.random = NewRandom(seed)
+		w.Rules = HARD_RULES
+		w.Hazards[GridRoom(0, 1)] = BAT
+		w.markAround(w.SoundLayer, GridRoom(0, 1))
+
+		// The bat rolls a new roost, which may be the old one,
+		// but is never where it drops the player
+		if _, outcome := w.Move(UP); outcome != GRABBED_BY_BAT {
+			t.Fatalf("seed %d: got %d, expected a bat", seed, outcome)
+		}
+
+		if countHazards(&w, BAT) != 1 || w.Hazards[w.PlayerRoom] != EMPTY {
+			t.Fatalf("seed %d: bat roosted badly", seed)
+		}
+
+		if w.Hazards[GridRoom(0, 1)] == EMPTY {
+			moves += 1
+		}
+
+		for room := range w.SoundLayer {
+			if w.SoundLayer[room] != isHazardNextTo(&w, BAT, room) {
+				t.Fatalf("seed %d: sound wrong in room %d", seed, room)
+			}
+		}
+	}
+
+	if moves == 0 {
+		t.Error("bats never moved")
+	}
+}
+
+func TestBatDropsPlayerInFullCave(t *testing.T) {
+
+	w := newTestWorld()
+	w.random = stuckSource{}
+	w.PlayerRoom = GridRoom(1, 0)
+	for room := range w.Hazards {
+		if room != w.PlayerRoom && w.Hazards[room] == EMPTY {
+			w.Hazards[room] = PIT
+		}
+	}
+
+	w.Hazards[GridRoom(1, 1)] = BAT
+
+	// The rolls all land on a pit, so the bat steps
+	// through the cave to the only empty room
+	if _, outcome := w.Move(UP); outcome != GRABBED_BY_BAT || w.PlayerRoom != GridRoom(1, 0) {
+		t.Errorf("got %d in room %d, expected a bat to drop the player in room %d", outcome, w.PlayerRoom, GridRoom(1, 0))
+	}
+}
+
+func TestMove(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		hazard    uint8
+		direction uint
+		isMoved   bool
+		outcome   Outcome
+	}{
+		{"empty", EMPTY, UP, true, SAFE},
+		{"wall", EMPTY, DOWN, false, SAFE},
+		{"pit", PIT, RIGHT, true, FELL_INTO_PIT},
+		{"wumpus", WUMPUS, UP, true, EATEN_BY_WUMPUS},
+		{"bat", BAT, UP, true, GRABBED_BY_BAT},
+	}
+
+	for _, test := range tests {
+		w := newTestWorld()
+		if next, ok := w.Topology.Neighbour(w.PlayerRoom, test.direction); ok && test.hazard != EMPTY {
+			w.Hazards[next] = test.hazard
+		}
+
+		isMoved, outcome := w.Move(test.direction)
+		if isMoved != test.isMoved || outcome != test.outcome {
+			t.Errorf("%s: got %t, %d, expected %t, %d", test.name, isMoved, outcome, test.isMoved, test.outcome)
+		}
+
+		if !w.Visited[GridRoom(0, 0)] {
+			t.Errorf("%s: start room not visited", test.name)
+		}
+
+		isInPlay := test.outcome == SAFE || test.outcome == GRABBED_BY_BAT
+		if w.IsInPlay != isInPlay {
+			t.Errorf("%s: in play %t, expected %t", test.name, w.IsInPlay, isInPlay)
+		}
+
+		// Bats drop the player in an empty room
+		if test.outcome == GRABBED_BY_BAT && w.Hazards[w.PlayerRoom] != EMPTY {
+			t.Errorf("%s: dropped on a hazard", test.name)
+		}
+	}
+}
+
+func TestMoveThroughTunnels(t *testing.T) {
+
+	w := New(Dodecahedron{}, NewRandom(1))
+	for room := range w.Hazards {
+		w.Hazards[room] = EMPTY
+	}
+
+	// Left, up and right take the first, second and third tunnels
+	for i, direction := range []uint{LEFT, UP, RIGHT} {
+		w.PlayerRoom = 0
+		if isMoved, _ := w.Move(direction); !isMoved || w.PlayerRoom != DODECAHEDRON_TUNNELS[0][i] {
+			t.Errorf("direction %d: moved to room %d", direction, w.PlayerRoom)
+		}
+	}
+
+	// There's no fourth tunnel
+	w.PlayerRoom = 0
+	if isMoved, _ := w.Move(DOWN); isMoved || w.PlayerRoom != 0 {
+		t.Error("moved down a tunnel")
+	}
+}
+
+func TestFire(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		wumpus  int
+		arrows  uint
+		path    []uint
+		outcome Outcome
+		left    uint
+	}{
+		{"straight hit", GridRoom(0, 3), 2, []uint{UP, UP, UP}, KILLED_WUMPUS, 1},
+		{"crooked hit", GridRoom(2, 1), 2, []uint{RIGHT, UP, RIGHT}, KILLED_WUMPUS, 1},
+		{"miss", GridRoom(7, 7), 2, []uint{UP, UP}, MISSED_WUMPUS, 1},
+		{"out of range", GridRoom(0, 6), 2, []uint{UP, UP, UP, UP, UP, UP}, MISSED_WUMPUS, 1},
+		{"last arrow", GridRoom(7, 7), 1, []uint{RIGHT}, OUT_OF_ARROWS, 0},
+		{"shot self", GridRoom(7, 7), 2, []uint{UP, RIGHT, DOWN, LEFT}, SHOT_SELF, 1},
+		{"wall", GridRoom(7, 7), 2, []uint{DOWN}, SAFE, 2},
+		{"no arrows", GridRoom(0, 1), 0, []uint{UP}, SAFE, 0},
+	}
+
+	for _, test := range tests {
+		w := newTestWorld()
+		w.Hazards[w.WumpusRoom] = EMPTY
+		w.WumpusRoom = test.wumpus
+		w.Hazards[w.WumpusRoom] = WUMPUS
+		w.Arrows = test.arrows
+
+		if outcome := w.Fire(test.path); outcome != test.outcome {
+			t.Errorf("%s: got %d, expected %d", test.name, outcome, test.outcome)
+		}
+
+		if w.Arrows != test.left {
+			t.Errorf("%s: %d arrows left, expected %d", test.name, w.Arrows, test.left)
+		}
+
+		if test.outcome == MISSED_WUMPUS && !w.IsWumpusAwake {
+			t.Errorf("%s: miss didn't wake the Wumpus", test.name)
+		}
+
+		isInPlay := test.outcome == SAFE || test.outcome == MISSED_WUMPUS
+		if w.IsInPlay != isInPlay {
+			t.Errorf("%s: in play %t, expected %t", test.name, w.IsInPlay, isInPlay)
+		}
+	}
+}
+
+func TestArrowFlight(t *testing.T) {
+
+	w := newTestWorld()
+	w.Fire(w.StraightPath(RIGHT))
+
+	// The arrow flies its full range, and no further
+	expected := []int{GridRoom(1, 0), GridRoom(2, 0), GridRoom(3, 0), GridRoom(4, 0), GridRoom(5, 0)}
+	if !reflect.DeepEqual(w.ArrowFlight, expected) {
+		t.Errorf("arrow flew through %v, expected %v", w.ArrowFlight, expected)
+	}
+
+	// A wall stops it
+	w.PlayerRoom = GridRoom(6, 0)
+	w.Fire([]uint{RIGHT, RIGHT, RIGHT})
+	if !reflect.DeepEqual(w.ArrowFlight, []int{GridRoom(7, 0)}) {
+		t.Errorf("arrow flew through %v, expected the one room", w.ArrowFlight)
+	}
+}
+
+func TestFeltSensesStayPut(t *testing.T) {
+
+	w := newTestWorld()
+	w.Rules.IsWumpusRoaming = true
+	w.Hazards[w.WumpusRoom] = EMPTY
+	w.WumpusRoom = GridRoom(1, 1)
+	w.Hazards[w.WumpusRoom] = WUMPUS
+	w.markAround(w.StinkLayer, w.WumpusRoom)
+
+	// Smell the Wumpus next door, then walk on
+	w.Move(UP)
+	w.Move(UP)
+	if stink, _, _ := w.FeltIn(GridRoom(0, 1)); !stink {
+		t.Fatal("stink not felt")
+	}
+
+	// The Wumpus wanders off, but the player
+	// remembers what they smelled
+	w.IsWumpusAwake = true
+	for i := 0; i < 100 && w.WumpusRoom == GridRoom(1, 1); i++ {
+		w.WumpusTurn()
+	}
+
+	if w.WumpusRoom == GridRoom(1, 1) {
+		t.Fatal("Wumpus never moved")
+	}
+
+	if stink, _, _ := w.FeltIn(GridRoom(0, 1)); !stink {
+		t.Error("stink forgotten after the Wumpus moved")
+	}
+
+	if stink, _, _ := w.FeltIn(GridRoom(5, 5)); stink {
+		t.Error("stink felt in a room not visited")
+	}
+}
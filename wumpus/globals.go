@@ -10,31 +10,23 @@
  package main
 
 import (
-	"machine"
 	"time"
-	"wumpus/ht16k33"
+	"wumpus/display"
+	"wumpus/engine"
 )
 
 /*
  * GLOBALS
  */
-// Game board maps
 var (
-	hazards [8][8]uint8
-	visited [8][8]bool
-	stinkLayer [8][8]bool
-	soundLayer [8][8]bool
-	draughtLayer [8][8]bool
+	// Game state: board maps and player position
+	world engine.World
 
 	// Player state
-	playerX uint
-	playerY uint
-	lastMoveDirection uint
-	isInPlay bool
 	isPlayerPixelOn bool
 
 	// Display instance
-	matrix ht16k33.HT16K33
+	matrix display.Display
 
 	// Fire button debounce controls
 	debounceButtonCount time.Time
@@ -42,10 +34,39 @@ var (
 	isJoystickCentred bool = true
 	debounceButtonFlag bool = false
 
-	PIN_Y machine.ADC = machine.ADC{Pin: machine.GP27}
-	PIN_X machine.ADC = machine.ADC{Pin: machine.GP26}
-
 	// FROM 1.0.1
 	gamesWon uint
 	gamesLost uint
+
+	// FROM 1.1.0
+	// The current board's seed, and the seed, cave and
+	// rules of a game code entered by the player
+	gameSeed uint32
+	requestedSeed uint32
+	requestedCave engine.Topology
+	requestedRules engine.Rules
+	isSeedRequested bool
+
+	// Cave layout choice, and whether the player is
+	// plotting an arrow's path, and the path so far
+	isCaveMode bool
+	isAiming bool
+	arrowPath []uint
+
+	// Difficulty level, and the rules for the custom level
+	level uint = LEVEL_NORMAL
+	customRules engine.Rules = engine.NORMAL_RULES
+
+	// The stats display, if there is one, and what it's showing
+	stats display.TextDisplay
+	statsText string
+
+	// Game recording and playback, and the last game's length
+	gameStart time.Time
+	gameLength time.Duration
+	recorder *engine.Recorder
+	replay *engine.Replay
+
+	// Keeps a greyscale matrix refreshed, if it needs it
+	refresher *display.Refresher
 )
@@ -0,0 +1,221 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package graphics
+
+// Shown in place of characters the segment sets lack: three bars
+var SEGMENT7_UNKNOWN byte = 0x49
+var SEGMENT14_UNKNOWN uint16 = 0x00C9
+
+// Characters for 7-segment digits, from Ascii 32. Bits 0-6 are
+// segments A-G, clockwise from the top then the middle bar;
+// bit 7 is the decimal point. As with `CHARSET`, the degree
+// sign takes the place of Ascii 127
+var SEGMENT7_CHARSET [96]byte = [96]byte{
+	0x00, // space - Ascii 32
+	0x86, // !
+	0x22, // "
+	0x7E, // #
+	0x6D, // $
+	0xD2, // %
+	0x46, // &
+	0x20, // '
+	0x29, // (
+	0x0B, // )
+	0x21, // *
+	0x70, // +
+	0x10, // ,
+	0x40, // -
+	0x80, // .
+	0x52, // /
+	0x3F, // 0
+	0x06, // 1
+	0x5B, // 2
+	0x4F, // 3
+	0x66, // 4
+	0x6D, // 5
+	0x7D, // 6
+	0x07, // 7
+	0x7F, // 8
+	0x6F, // 9
+	0x09, // :
+	0x0D, // ;
+	0x61, // <
+	0x48, // =
+	0x43, // >
+	0xD3, // ?
+	0x5F, // @
+	0x77, // A
+	0x7C, // B
+	0x39, // C
+	0x5E, // D
+	0x79, // E
+	0x71, // F
+	0x3D, // G
+	0x76, // H
+	0x30, // I
+	0x1E, // J
+	0x75, // K
+	0x38, // L
+	0x15, // M
+	0x37, // N
+	0x3F, // O
+	0x73, // P
+	0x6B, // Q
+	0x33, // R
+	0x6D, // S
+	0x78, // T
+	0x3E, // U
+	0x3E, // V
+	0x2A, // W
+	0x76, // X
+	0x6E, // Y
+	0x5B, // Z
+	0x39, // [
+	0x64, // \
+	0x0F, // ]
+	0x23, // ^
+	0x08, // _
+	0x02, // `
+	0x5F, // a
+	0x7C, // b
+	0x58, // c
+	0x5E, // d
+	0x7B, // e
+	0x71, // f
+	0x6F, // g
+	0x74, // h
+	0x10, // i
+	0x0C, // j
+	0x75, // k
+	0x30, // l
+	0x14, // m
+	0x54, // n
+	0x5C, // o
+	0x73, // p
+	0x67, // q
+	0x50, // r
+	0x6D, // s
+	0x78, // t
+	0x1C, // u
+	0x1C, // v
+	0x14, // w
+	0x76, // x
+	0x6E, // y
+	0x5B, // z
+	0x46, // {
+	0x30, // |
+	0x70, // }
+	0x01, // ~
+	0x63, // degree sign
+}
+
+// Characters for 14-segment alphanumeric digits, from Ascii 32.
+// Bits 0-5 are the outer segments A-F, clockwise from the top;
+// bits 6 and 7 the left and right middle bars, G1 and G2; bits
+// 8-13 the inner diagonals and verticals, H-N; bit 14 is the
+// decimal point
+var SEGMENT14_CHARSET [96]uint16 = [96]uint16{
+	0x0000, // space - Ascii 32
+	0x0006, // !
+	0x0220, // "
+	0x12CE, // #
+	0x12ED, // $
+	0x0C24, // %
+	0x235D, // &
+	0x0400, // '
+	0x2400, // (
+	0x0900, // )
+	0x3FC0, // *
+	0x12C0, // +
+	0x0800, // ,
+	0x00C0, // -
+	0x4000, // .
+	0x0C00, // /
+	0x0C3F, // 0
+	0x0006, // 1
+	0x00DB, // 2
+	0x008F, // 3
+	0x00E6, // 4
+	0x2069, // 5
+	0x00FD, // 6
+	0x0007, // 7
+	0x00FF, // 8
+	0x00EF, // 9
+	0x1200, // :
+	0x0A00, // ;
+	0x2400, // <
+	0x00C8, // =
+	0x0900, // >
+	0x1083, // ?
+	0x02BB, // @
+	0x00F7, // A
+	0x128F, // B
+	0x0039, // C
+	0x120F, // D
+	0x00F9, // E
+	0x0071, // F
+	0x00BD, // G
+	0x00F6, // H
+	0x1209, // I
+	0x001E, // J
+	0x2470, // K
+	0x0038, // L
+	0x0536, // M
+	0x2136, // N
+	0x003F, // O
+	0x00F3, // P
+	0x203F, // Q
+	0x20F3, // R
+	0x00ED, // S
+	0x1201, // T
+	0x003E, // U
+	0x0C30, // V
+	0x2836, // W
+	0x2D00, // X
+	0x1500, // Y
+	0x0C09, // Z
+	0x0039, // [
+	0x2100, // \
+	0x000F, // ]
+	0x0C03, // ^
+	0x0008, // _
+	0x0100, // `
+	0x1058, // a
+	0x2078, // b
+	0x00D8, // c
+	0x088E, // d
+	0x0858, // e
+	0x0071, // f
+	0x048E, // g
+	0x1070, // h
+	0x1000, // i
+	0x000E, // j
+	0x3600, // k
+	0x0030, // l
+	0x10D4, // m
+	0x1050, // n
+	0x00DC, // o
+	0x0170, // p
+	0x0486, // q
+	0x0050, // r
+	0x2088, // s
+	0x0078, // t
+	0x001C, // u
+	0x2004, // v
+	0x2814, // w
+	0x28C0, // x
+	0x200C, // y
+	0x0848, // z
+	0x0949, // {
+	0x1200, // |
+	0x2489, // }
+	0x0520, // ~
+	0x00E3, // degree sign
+}
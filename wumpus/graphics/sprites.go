@@ -11,6 +11,13 @@ package graphics
 
 type Sprite [8]byte
 
+// A graphic for bicolor matrices: one layer for each LED colour.
+// Pixels lit in both layers show yellow
+type ColourSprite struct {
+	Green Sprite
+	Red   Sprite
+}
+
 var BAT_01 Sprite = Sprite{0x1E, 0x38, 0x6F, 0x3E, 0x3E, 0x6F, 0x38, 0x1E}
 var BAT_02 Sprite = Sprite{0xF0, 0x38, 0x6F, 0x3E, 0x3E, 0x6F, 0x38, 0xF0}
 
@@ -71,6 +78,19 @@ var BEGIN_05 Sprite = Sprite{0x00, 0x00, 0x00, 0x1F, 0x23, 0x20, 0x20, 0x1F}
 var BEGIN_06 Sprite = Sprite{0x00, 0x00, 0x00, 0x1F, 0x20, 0x23, 0x20, 0x1F}
 var BEGIN_07 Sprite = Sprite{0x00, 0x00, 0x00, 0x1F, 0x20, 0x23, 0x20, 0x1F}
 
+var GRID_MODE Sprite = Sprite{0xFE, 0x92, 0x92, 0xFE, 0x92, 0x92, 0xFE, 0x00}
+var CAVE_MODE Sprite = Sprite{0x30, 0x4C, 0x42, 0x82, 0x82, 0x42, 0x4C, 0x30}
+
+// Bicolor versions: a red headstone on green grass, and a yellow trophy
+var GRAVE_COLOUR ColourSprite = ColourSprite{
+	Green: Sprite{0x01, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03},
+	Red:   Sprite{0x00, 0x00, 0x7C, 0x90, 0xBC, 0x90, 0x7C, 0x00},
+}
+var TROPHY_COLOUR ColourSprite = ColourSprite{Green: TROPHY, Red: TROPHY}
+
+// Shown in place of characters that have no glyph
+var UNKNOWN_GLYPH []byte = []byte{0xfe, 0x82, 0x82, 0x82, 0xfe, 0x00}
+
 var CHARSET [128][]byte = [128][]byte{
 	[]byte{0x00, 0x00, 0x00},                   // space - Ascii 32
 	[]byte{0xfa, 0x00},                         // !
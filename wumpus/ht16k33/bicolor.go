@@ -0,0 +1,130 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Pixel colours on a bicolor matrix. Yellow lights both LEDs
+	HT16K33_COLOUR_OFF    uint = display.COLOUR_OFF
+	HT16K33_COLOUR_GREEN  uint = display.COLOUR_GREEN
+	HT16K33_COLOUR_RED    uint = display.COLOUR_RED
+	HT16K33_COLOUR_YELLOW uint = display.COLOUR_YELLOW
+)
+
+/*
+ * @brief Set whether the matrix is a red/green bicolor one, such as
+ *        Adafruit's bicolor backpack, which uses the display RAM
+ *        bytes a single-colour matrix leaves empty for its red LEDs.
+ *        In bicolor mode, `Plot()`, `DrawSprite()` and `Print()`
+ *        draw in green. Clears the frame buffer.
+ *
+ * @param isBicolor: `true` for a bicolor matrix, otherwise `false`.
+ */
+func (p *HT16K33) SetBicolor(isBicolor bool) {
+
+	p.lock.Lock()
+	p.isBicolor = isBicolor
+	p.lock.Unlock()
+
+	p.Clear()
+}
+
+/*
+ * @brief Is the matrix a bicolor one?
+ *
+ * @returns `true` if bicolor mode is set, otherwise `false`.
+ */
+func (p *HT16K33) IsBicolor() bool {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.isBicolor
+}
+
+/*
+ * @brief Set a specific pixel's colour. On a single-colour matrix,
+ *        any colour but `HT16K33_COLOUR_OFF` lights the pixel.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:      The pixel's X co-ordinate.
+ * @param y:      The pixel's Y co-ordinate.
+ * @param colour: `HT16K33_COLOUR_OFF`, `HT16K33_COLOUR_GREEN`,
+ *                `HT16K33_COLOUR_RED` or `HT16K33_COLOUR_YELLOW`.
+ */
+func (p *HT16K33) PlotColour(x uint, y uint, colour uint) {
+
+	if !p.isBicolor {
+		p.Plot(x, y, colour != HT16K33_COLOUR_OFF)
+		return
+	}
+
+	p.Plot(x, y, colour&HT16K33_COLOUR_GREEN != 0)
+	if colour&HT16K33_COLOUR_RED != 0 {
+		p.redBuffer[x] |= 1 << y
+	} else {
+		p.redBuffer[x] &= ^(1 << y)
+	}
+}
+
+/*
+ * @brief Get a specific pixel's colour from the frame buffer.
+ *        On a single-colour matrix, lit pixels are green.
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `HT16K33_COLOUR_OFF`, `HT16K33_COLOUR_GREEN`,
+ *          `HT16K33_COLOUR_RED` or `HT16K33_COLOUR_YELLOW`.
+ */
+func (p *HT16K33) GetColour(x uint, y uint) uint {
+
+	colour := HT16K33_COLOUR_OFF
+	if p.Get(x, y) {
+		colour |= HT16K33_COLOUR_GREEN
+	}
+
+	if p.isBicolor && x < 8 && y < 8 && p.redBuffer[x]&(1<<y) != 0 {
+		colour |= HT16K33_COLOUR_RED
+	}
+
+	return colour
+}
+
+/*
+ * @brief Write a colour graphic to the frame buffer and update the
+ *        display. On a single-colour matrix, both layers are shown.
+ *
+ * @param sprite: The graphic.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *HT16K33) DrawColourSprite(sprite *graphics.ColourSprite) error {
+
+	p.clearLayers()
+	if !p.isBicolor {
+		for i := 0; i < 8; i++ {
+			p.buffer[i] = sprite.Green[i] | sprite.Red[i]
+		}
+
+		return p.Draw()
+	}
+
+	p.buffer = sprite.Green
+	p.redBuffer = sprite.Red
+	return p.Draw()
+}
@@ -0,0 +1,111 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"testing"
+	"wumpus/graphics"
+)
+
+/*
+ * @brief Make the RAM write for a bicolor frame.
+ *
+ * @param green: The green LEDs, a column per byte.
+ * @param red:   The red LEDs, a column per byte.
+ *
+ * @returns The write: the RAM address, then each column's
+ *          green and red bytes.
+ */
+func colourWrite(green [8]byte, red [8]byte) []byte {
+
+	data := make([]byte, 17)
+	for i := 0; i < 8; i++ {
+		data[i*2+1] = green[i]
+		data[i*2+2] = red[i]
+	}
+
+	return data
+}
+
+func TestPlotColour(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		colour  uint
+		green   byte
+		red     byte
+		cleared []byte
+	}{
+		{"off", HT16K33_COLOUR_OFF, 0x00, 0x00, nil},
+		{"green", HT16K33_COLOUR_GREEN, 0x08, 0x00, []byte{0x04, 0x00}},
+		{"red", HT16K33_COLOUR_RED, 0x00, 0x08, []byte{0x05, 0x00}},
+		{"yellow", HT16K33_COLOUR_YELLOW, 0x08, 0x08, []byte{0x04, 0x00, 0x00}},
+	}
+
+	for _, test := range tests {
+		matrix, bus := newTestMatrix(t)
+		matrix.SetBicolor(true)
+
+		// Pixel (2,3) is bit 3 of the third column's bytes
+		matrix.PlotColour(2, 3, test.colour)
+		if colour := matrix.GetColour(2, 3); colour != test.colour {
+			t.Errorf("%s pixel read back as %d", test.name, colour)
+		}
+
+		_ = matrix.Draw()
+		green, red := [8]byte{}, [8]byte{}
+		green[2], red[2] = test.green, test.red
+		checkWrites(t, bus, colourWrite(green, red))
+
+		// Turning the pixel off clears whichever LEDs were lit
+		matrix.PlotColour(2, 3, HT16K33_COLOUR_OFF)
+		_ = matrix.Draw()
+		if test.cleared == nil {
+			checkWrites(t, bus)
+		} else {
+			checkWrites(t, bus, test.cleared)
+		}
+	}
+}
+
+func TestPlotColourOnOneColour(t *testing.T) {
+
+	// Any colour lights a single-colour matrix' pixel
+	for _, colour := range []uint{HT16K33_COLOUR_GREEN, HT16K33_COLOUR_RED, HT16K33_COLOUR_YELLOW} {
+		matrix, bus := newTestMatrix(t)
+		matrix.PlotColour(2, 3, colour)
+		_ = matrix.Draw()
+		checkWrites(t, bus, pixelWrite(2, 3))
+	}
+}
+
+func TestDrawColourSprite(t *testing.T) {
+
+	sprite := graphics.ColourSprite{
+		Green: [8]byte{0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80},
+		Red:   [8]byte{0x00, 0x02, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00},
+	}
+
+	matrix, bus := newTestMatrix(t)
+	matrix.SetBicolor(true)
+	_ = matrix.DrawColourSprite(&sprite)
+	checkWrites(t, bus, colourWrite(sprite.Green, sprite.Red))
+
+	// Sprites and text are green
+	matrix, bus = newTestMatrix(t)
+	matrix.SetBicolor(true)
+	_ = matrix.DrawSprite(&graphics.Sprite{0xFF})
+	checkWrites(t, bus, colourWrite([8]byte{0xFF}, [8]byte{}))
+
+	// A single-colour matrix shows both layers
+	matrix, bus = newTestMatrix(t)
+	_ = matrix.DrawColourSprite(&sprite)
+	checkWrites(t, bus, ramWrite(0x80, 0x01, 0x02, 0x00, 0x00, 0x00, 0x00, 0x40))
+}
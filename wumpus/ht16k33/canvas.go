@@ -0,0 +1,264 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"fmt"
+	"time"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+/*
+ * Several 8x8 HT16K33 matrices tiled into one larger display.
+ * Panels are laid out in rows, left to right, from the top row
+ * down, in the order their addresses are given. As with a single
+ * matrix, (0,0) is the bottom left corner of the whole canvas
+ */
+type Canvas struct {
+	panels  []HT16K33
+	columns uint
+}
+
+/*
+ * @brief Convenience method to instantiate a Canvas struct.
+ *        For a row of panels, eg. a 32x8 scoreboard, pass the number
+ *        of addresses as `columns`; for a stack, pass 1; for a square
+ *        of four panels giving 16x16 pixels, pass 2.
+ *
+ * @param bus:       The host I2C bus the panels share.
+ * @param addresses: Each panel's 7-bit I2C address, eg. `0x70` to `0x73`.
+ * @param columns:   How many panels make up each row.
+ *
+ * @returns The canvas, and an error if the panels don't fill every
+ *          row, or if any panel did not respond.
+ */
+func NewCanvas(bus Bus, addresses []uint8, columns uint) (Canvas, error) {
+
+	if columns == 0 || columns > uint(len(addresses)) {
+		columns = uint(len(addresses))
+	}
+
+	// A part-filled row would be left off the canvas
+	if columns > 0 && uint(len(addresses))%columns != 0 {
+		return Canvas{}, fmt.Errorf("%d panels don't fill rows of %d", len(addresses), columns)
+	}
+
+	var err error
+	panels := make([]HT16K33, len(addresses))
+	for i, address := range addresses {
+		var panelErr error
+		panels[i], panelErr = New(bus, address)
+		if err == nil {
+			err = panelErr
+		}
+	}
+
+	return Canvas{
+		panels:  panels,
+		columns: columns,
+	}, err
+}
+
+/*
+ * @brief Get the canvas' width.
+ *
+ * @returns The width in pixels.
+ */
+func (c *Canvas) Width() uint {
+
+	return c.columns * 8
+}
+
+/*
+ * @brief Get the canvas' height.
+ *
+ * @returns The height in pixels.
+ */
+func (c *Canvas) Height() uint {
+
+	if c.columns == 0 {
+		return 0
+	}
+
+	return uint(len(c.panels)) / c.columns * 8
+}
+
+/*
+ * @brief Power on every panel, set a default brightness,
+ *        and clear the canvas.
+ *
+ * @returns An error if a panel could not be reached.
+ */
+func (c *Canvas) Init() error {
+
+	for i := range c.panels {
+		if err := c.panels[i].Init(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Set every panel's brightness.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (very bright).
+ *
+ * @returns An error if a panel could not be reached.
+ */
+func (c *Canvas) SetBrightness(brightness uint) error {
+
+	for i := range c.panels {
+		if err := c.panels[i].SetBrightness(brightness); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Turn a specific pixel on the canvas on or off.
+ *        Pixels off the canvas are ignored.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param isSet: `true` to light the pixel, `false` to clear it.
+ */
+func (c *Canvas) Plot(x uint, y uint, isSet bool) {
+
+	if x >= c.Width() || y >= c.Height() {
+		return
+	}
+
+	// Panel rows run from the top down
+	row := (c.Height() - 1 - y) / 8
+	panel := row*c.columns + x/8
+	c.panels[panel].Plot(x%8, y%8, isSet)
+}
+
+/*
+ * @brief Is a specific pixel on the canvas lit?
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `true` if the pixel is set, `false` if it's clear
+ *          or off the canvas.
+ */
+func (c *Canvas) Get(x uint, y uint) bool {
+
+	if x >= c.Width() || y >= c.Height() {
+		return false
+	}
+
+	row := (c.Height() - 1 - y) / 8
+	panel := row*c.columns + x/8
+	return c.panels[panel].Get(x%8, y%8)
+}
+
+/*
+ * @brief Write a graphic pattern anywhere on the canvas,
+ *        across panel edges if need be, and update the display.
+ *
+ * @param sprite: A graphic stored as an [8]byte array.
+ * @param x:      The X co-ordinate of the sprite's left edge.
+ * @param y:      The Y co-ordinate of the sprite's bottom edge.
+ *
+ * @returns An error if a panel could not be reached.
+ */
+func (c *Canvas) DrawSprite(sprite *graphics.Sprite, x uint, y uint) error {
+
+	for i, column := range sprite {
+		for j := uint(0); j < 8; j++ {
+			c.Plot(x+uint(i), y+j, column&(1<<j) != 0)
+		}
+	}
+
+	return c.Draw()
+}
+
+/*
+ * @brief Scroll a text string across the full width of the canvas,
+ *        centred vertically. Text that fits on the canvas is shown
+ *        in the middle, without scrolling.
+ *
+ * @param text: The string to scroll.
+ *
+ * @returns An error if a panel could not be reached.
+ */
+func (c *Canvas) Print(text string) error {
+
+	// Render the text as a row of columns
+	src_buffer := display.TextColumns(text)
+	length := len(src_buffer)
+	width := int(c.Width())
+	base := (c.Height() - 8) / 2
+	if length <= width {
+		src_buffer = display.CentreColumns(src_buffer, width)
+		length = width
+	}
+
+	// Animate the line by repeatedly sending a canvas'
+	// width of the output buffer to the panels
+	cursor := 0
+	for {
+		c.Clear()
+		for i := 0; i < width && cursor+i < length; i++ {
+			for j := uint(0); j < 8; j++ {
+				c.Plot(uint(i), base+j, src_buffer[cursor+i]&(1<<j) != 0)
+			}
+		}
+
+		if err := c.Draw(); err != nil {
+			return err
+		}
+
+		cursor += 1
+		if cursor > length-width {
+			break
+		}
+
+		// Pause between frames
+		time.Sleep(printPeriod)
+	}
+
+	return nil
+}
+
+/*
+ * @brief Clear every panel's frame buffer.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (c *Canvas) Clear() {
+
+	for i := range c.panels {
+		c.panels[i].Clear()
+	}
+}
+
+/*
+ * @brief Write every panel's frame buffer to its display.
+ *
+ * @returns An error if a panel could not be reached.
+ */
+func (c *Canvas) Draw() error {
+
+	for i := range c.panels {
+		if err := c.panels[i].Draw(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+/*
+ * @brief Make a canvas on a FakeBus.
+ *
+ * @param t:         The test.
+ * @param addresses: Each panel's address.
+ * @param columns:   How many panels make up each row.
+ *
+ * @returns The canvas and its bus.
+ */
+func newTestCanvas(t *testing.T, addresses []uint8, columns uint) (*Canvas, *FakeBus) {
+
+	bus := FakeBus{}
+	canvas, err := NewCanvas(&bus, addresses, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &canvas, &bus
+}
+
+/*
+ * @brief Collect the last RAM write sent to each panel.
+ *
+ * @param bus: The bus the panels share.
+ *
+ * @returns Each panel's RAM write, by address.
+ */
+func panelWrites(bus *FakeBus) map[uint16][]byte {
+
+	writes := map[uint16][]byte{}
+	for _, transaction := range bus.Transactions {
+		if len(transaction.Data) == 17 {
+			writes[transaction.Address] = transaction.Data
+		}
+	}
+
+	return writes
+}
+
+func TestCanvasSize(t *testing.T) {
+
+	tests := []struct {
+		columns       uint
+		width, height uint
+	}{
+		{0, 32, 8},
+		{4, 32, 8},
+		{2, 16, 16},
+		{1, 8, 32},
+	}
+
+	for _, test := range tests {
+		canvas, _ := newTestCanvas(t, []uint8{0x70, 0x71, 0x72, 0x73}, test.columns)
+		if canvas.Width() != test.width || canvas.Height() != test.height {
+			t.Errorf("%d columns: %dx%d, expected %dx%d", test.columns, canvas.Width(), canvas.Height(), test.width, test.height)
+		}
+	}
+}
+
+func TestCanvasPlot(t *testing.T) {
+
+	// A square of four panels: 0x70 and 0x71 on top
+	canvas, bus := newTestCanvas(t, []uint8{0x70, 0x71, 0x72, 0x73}, 2)
+
+	// Light each panel's corner that is a corner of the canvas
+	canvas.Plot(0, 0, true)
+	canvas.Plot(15, 0, true)
+	canvas.Plot(0, 15, true)
+	canvas.Plot(15, 15, true)
+
+	// Pixels off the canvas are ignored
+	canvas.Plot(16, 0, true)
+	canvas.Plot(0, 16, true)
+	_ = canvas.Draw()
+
+	expected := map[uint16][]byte{
+		0x70: ramWrite(0x40),
+		0x71: ramWrite(0, 0, 0, 0, 0, 0, 0, 0x40),
+		0x72: ramWrite(0x80),
+		0x73: ramWrite(0, 0, 0, 0, 0, 0, 0, 0x80),
+	}
+
+	if writes := panelWrites(bus); !reflect.DeepEqual(writes, expected) {
+		t.Errorf("wrote % X, expected % X", writes, expected)
+	}
+
+	// Pixels read back from the panels they were plotted on
+	if !canvas.Get(0, 0) || !canvas.Get(15, 0) || !canvas.Get(0, 15) || !canvas.Get(15, 15) {
+		t.Error("corner pixels not read back")
+	}
+
+	if canvas.Get(1, 0) || canvas.Get(16, 0) {
+		t.Error("unlit pixels read back")
+	}
+}
+
+func TestCanvasDrawSprite(t *testing.T) {
+
+	// A sprite across the edge of two panels in a row
+	canvas, bus := newTestCanvas(t, []uint8{0x70, 0x71}, 0)
+	sprite := graphics.Sprite{0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x80}
+	_ = canvas.DrawSprite(&sprite, 4, 0)
+
+	expected := map[uint16][]byte{
+		0x70: ramWrite(0, 0, 0, 0, 0x80, 0x01, 0x02, 0x04),
+		0x71: ramWrite(0x08, 0x10, 0x20, 0x40),
+	}
+
+	if writes := panelWrites(bus); !reflect.DeepEqual(writes, expected) {
+		t.Errorf("wrote % X, expected % X", writes, expected)
+	}
+}
+
+func TestNewCanvasReportsMissingPanel(t *testing.T) {
+
+	bus := FakeBus{Err: errors.New("no ack")}
+	if _, err := NewCanvas(&bus, []uint8{0x70, 0x71}, 0); err == nil {
+		t.Error("no error from missing panels")
+	}
+}
+
+func TestNewCanvasReportsPartRow(t *testing.T) {
+
+	// Three panels can't make rows of two
+	bus := FakeBus{}
+	if _, err := NewCanvas(&bus, []uint8{0x70, 0x71, 0x72}, 2); err == nil {
+		t.Error("no error from a part-filled row")
+	}
+
+	if len(bus.Transactions) != 0 {
+		t.Errorf("%d transactions, expected none", len(bus.Transactions))
+	}
+}
+
+func FuzzCanvasPrint(f *testing.F) {
+
+	addTextSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		canvas, bus := newTestCanvas(t, []uint8{0x70, 0x71}, 2)
+		if err := canvas.Print(text); err != nil {
+			t.Fatal(err)
+		}
+
+		// Text that fits is shown once, across both panels
+		if len(display.TextColumns(text)) <= 16 && len(bus.Transactions) > 2 {
+			t.Errorf("%q scrolled through %d frames", text, len(bus.Transactions)/2)
+		}
+
+		checkPrinted(t, append(canvas.panels[0].buffer[:], canvas.panels[1].buffer[:]...), text)
+	})
+}
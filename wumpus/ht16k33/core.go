@@ -0,0 +1,217 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"fmt"
+)
+
+/*
+ * The host I2C bus: all the driver needs is to send bytes to
+ * the display. TinyGo's `*machine.I2C` satisfies this
+ */
+type Bus interface {
+	Tx(addr uint16, w, r []byte) error
+}
+
+/*
+ * What every HT16K33 display has in common, whatever is wired to
+ * the chip: the bus, the chip's commands and its key scan. The
+ * matrix and segment drivers embed this
+ */
+type core struct {
+	// Host I2C bus
+	bus Bus
+	// Internal data: I2C address, brightness level
+	address    uint8
+	brightness uint
+	// Display setup: power state and blink rate
+	isOn      bool
+	blinkRate uint
+	// Display RAM as last written, so that only changes are sent
+	ram       [16]byte
+	isRamSent bool
+	// Key scan: debounced keys, and the last keys read
+	// and how many times in a row they have been read
+	keys     uint64
+	lastKeys uint64
+	keyReads uint
+}
+
+/*
+ * @brief Set up the chip's shared state and check it's there.
+ *
+ * @param bus:     The host I2C bus.
+ * @param address: The chip's 7-bit I2C address. Defaults to `0x70`
+ *                 if out of range.
+ *
+ * @returns The core, and an error if the chip did not respond.
+ */
+func newCore(bus Bus, address uint8) (core, error) {
+
+	if address < 8 || address > 0xF0 {
+		address = HT16K33_ADDRESS
+	}
+
+	chip := core{
+		bus:        bus,
+		address:    address,
+		brightness: 15,
+	}
+
+	// Probe the chip by reading a byte of its display RAM
+	data := [1]byte{}
+	if err := bus.Tx(uint16(address), nil, data[:]); err != nil {
+		return chip, fmt.Errorf("no HT16K33 at 0x%02X: %w", address, err)
+	}
+
+	return chip, nil
+}
+
+/*
+ * @brief Turn the display on or off.
+ *        The display must be turned on before it can be used
+ *        (by calling `Draw()`).
+ *
+ * @param isOn: `true` to enable the display, `false` to turn it off.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (c *core) Power(isOn bool) error {
+
+	c.isOn = isOn
+	if isOn {
+		if err := c.i2cWriteByte(HT16K33_CMD_SYSTEM_ON); err != nil {
+			return err
+		}
+
+		// Turn the display on without losing the blink rate
+		return c.i2cWriteByte(HT16K33_CMD_DISPLAY_ON | byte(c.blinkRate<<1))
+	}
+
+	if err := c.i2cWriteByte(HT16K33_CMD_DISPLAY_OFF); err != nil {
+		return err
+	}
+
+	return c.i2cWriteByte(HT16K33_CMD_SYSTEM_OFF)
+}
+
+/*
+ * @brief Set the display's hardware blink rate.
+ *        The effect is immediate, and the display
+ *        stays on or off as it was.
+ *
+ * @param rate: `HT16K33_BLINK_OFF`, `HT16K33_BLINK_2HZ`,
+ *              `HT16K33_BLINK_1HZ` or `HT16K33_BLINK_HALFHZ`.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (c *core) SetBlink(rate uint) error {
+
+	if rate > HT16K33_BLINK_HALFHZ {
+		rate = HT16K33_BLINK_OFF
+	}
+
+	c.blinkRate = rate
+	command := HT16K33_CMD_BLINK | byte(rate<<1)
+	if c.isOn {
+		command |= HT16K33_CMD_DISPLAY_ON
+	}
+
+	return c.i2cWriteByte(command)
+}
+
+/*
+ * @brief Set the display's brightness.
+ *        The effect is immediate.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (very bright).
+ *                    Note that 0 does not turn off the display.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (c *core) SetBrightness(brightness uint) error {
+
+	if brightness > 15 {
+		brightness = 15
+	}
+
+	c.brightness = brightness
+	return c.i2cWriteByte(HT16K33_CMD_BRIGHTNESS | byte(brightness&0xFF))
+}
+
+/*
+ * @brief Write the display RAM, sending only the bytes that have
+ *        changed since the last write, or nothing if none have.
+ *
+ * @param ram: The new display RAM contents.
+ *
+ * @returns The bus error, if any.
+ */
+func (c *core) writeRam(ram *[16]byte) error {
+
+	// Find the run of bytes that differ, or take them all
+	// if the display RAM's contents aren't known
+	first, last := 0, len(ram)-1
+	if c.isRamSent {
+		for first <= last && ram[first] == c.ram[first] {
+			first += 1
+		}
+
+		for last >= first && ram[last] == c.ram[last] {
+			last -= 1
+		}
+
+		if first > last {
+			return nil
+		}
+	}
+
+	// Send the run after its start address
+	output_buffer := [17]byte{}
+	output_buffer[0] = HT16K33_FRAME_STORE_ADDRESS + byte(first)
+	copy(output_buffer[1:], ram[first:last+1])
+	if err := c.i2cWriteBlock(output_buffer[:last-first+2]); err != nil {
+		// Whatever got through, send it all next time
+		c.isRamSent = false
+		return err
+	}
+
+	c.ram = *ram
+	c.isRamSent = true
+	return nil
+}
+
+/*
+ * @brief Write a byte to I2C.
+ *
+ * @param value: The byte to write.
+ *
+ * @returns The bus error, if any.
+ */
+func (c *core) i2cWriteByte(value byte) error {
+
+	// Convenience function to write a single byte to the display
+	data := [1]byte{value}
+	return c.bus.Tx(uint16(c.address), data[:], nil)
+}
+
+/*
+ * @brief Write a series of bytes to I2C.
+ *
+ * @param value: A slice of the bytes to write.
+ *
+ * @returns The bus error, if any.
+ */
+func (c *core) i2cWriteBlock(data []byte) error {
+
+	// Convenience function to write a 'count' bytes to the display
+	return c.bus.Tx(uint16(c.address), data, nil)
+}
@@ -0,0 +1,71 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+/*
+ * A single write made over a FakeBus
+ */
+type Transaction struct {
+	Address uint16
+	Data    []byte
+}
+
+/*
+ * A Bus that records every write made to it rather than sending it,
+ * so the bytes the driver generates can be checked off-device
+ */
+type FakeBus struct {
+	Transactions []Transaction
+	// Set to make every transaction fail, as if the display were missing
+	Err error
+	// Returned by reads, eg. key RAM contents
+	ReadData []byte
+}
+
+/*
+ * @brief Record a write. Reads are answered from `ReadData`,
+ *        then with zeros.
+ *
+ * @param addr: The target device's I2C address.
+ * @param w:    The bytes to write.
+ * @param r:    The buffer for any bytes read back.
+ *
+ * @returns `Err`, which is `nil` unless a failure is being faked.
+ */
+func (b *FakeBus) Tx(addr uint16, w, r []byte) error {
+
+	if b.Err != nil {
+		return b.Err
+	}
+
+	for i := range r {
+		r[i] = 0
+	}
+
+	copy(r, b.ReadData)
+
+	if len(w) == 0 {
+		return nil
+	}
+
+	// Take a copy: the driver reuses its buffers
+	data := make([]byte, len(w))
+	copy(data, w)
+	b.Transactions = append(b.Transactions, Transaction{Address: addr, Data: data})
+	return nil
+}
+
+/*
+ * @brief Discard all recorded writes.
+ */
+func (b *FakeBus) Reset() {
+
+	b.Transactions = nil
+}
@@ -0,0 +1,236 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"time"
+	"wumpus/display"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Pixel intensities for `PlotGrey()`
+	HT16K33_GREY_OFF    uint = display.GREY_OFF
+	HT16K33_GREY_DIM    uint = display.GREY_DIM
+	HT16K33_GREY_MEDIUM uint = display.GREY_MEDIUM
+	HT16K33_GREY_FULL   uint = display.GREY_FULL
+
+	// Each refresh cycle shows the dimmest bit plane for one
+	// slot and the brightest for two, so a pixel is lit for
+	// none, one, two or all three slots
+	HT16K33_GREY_SLOTS uint = 3
+
+	// Refreshes further apart than this would flicker,
+	// so the display falls back to plain on and off
+	HT16K33_GREY_MAX_PERIOD_MS int64 = 10
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// `HT16K33_GREY_MAX_PERIOD_MS` as a duration
+	greyMaxPeriod time.Duration = time.Duration(HT16K33_GREY_MAX_PERIOD_MS) * time.Millisecond
+)
+
+/*
+ * @brief Set how many intensities pixels plotted with `PlotGrey()`
+ *        can have. With 2, pixels are just on or off; with 3, dim
+ *        and medium pixels are both dim; with 4, every intensity
+ *        shows. Greyscale needs `Refresh()` to be called every few
+ *        milliseconds, and is ignored on a bicolor matrix.
+ *
+ * @param levels: The number of intensities, 2-4, including off.
+ */
+func (p *HT16K33) SetGreyscale(levels uint) {
+
+	if levels < 2 {
+		levels = 2
+	} else if levels > 4 {
+		levels = 4
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.greyLevels = levels
+	p.isRefreshOnTime = false
+	p.lastRefresh = time.Time{}
+}
+
+/*
+ * @brief Get the number of intensities set by `SetGreyscale()`.
+ *
+ * @returns The number of intensities, including off.
+ */
+func (p *HT16K33) GreyLevels() uint {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.greyLevels
+}
+
+/*
+ * @brief Does the matrix need `Refresh()` calls? Only when it
+ *        shows greyscale: otherwise they do nothing.
+ *
+ * @returns `true` if the matrix must be refreshed, otherwise `false`.
+ */
+func (p *HT16K33) NeedsRefresh() bool {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.greyLevels > 2 && !p.isBicolor
+}
+
+/*
+ * @brief Set a specific pixel's intensity. `Plot()`, `DrawSprite()`
+ *        and `Print()` light pixels at full intensity.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param level: `HT16K33_GREY_OFF`, `HT16K33_GREY_DIM`,
+ *               `HT16K33_GREY_MEDIUM` or `HT16K33_GREY_FULL`.
+ */
+func (p *HT16K33) PlotGrey(x uint, y uint, level uint) {
+
+	if level > HT16K33_GREY_FULL {
+		level = HT16K33_GREY_FULL
+	}
+
+	// This clears any dimming, leaving the pixel off or at full
+	p.Plot(x, y, level != HT16K33_GREY_OFF)
+	if level == HT16K33_GREY_OFF || level == HT16K33_GREY_FULL || p.greyLevels < 3 {
+		return
+	}
+
+	if p.greyLevels == 3 {
+		level = HT16K33_GREY_DIM
+	}
+
+	// Record how far the pixel is dimmed, one bit per plane
+	dim := HT16K33_GREY_FULL - level
+	for plane := range p.dimBuffer {
+		if dim&(1<<uint(plane)) != 0 {
+			p.dimBuffer[plane][x] |= 1 << y
+		}
+	}
+}
+
+/*
+ * @brief Get a specific pixel's intensity from the frame buffer.
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns The pixel's intensity, from `HT16K33_GREY_OFF`
+ *          to `HT16K33_GREY_FULL`.
+ */
+func (p *HT16K33) GetGrey(x uint, y uint) uint {
+
+	if !p.Get(x, y) {
+		return HT16K33_GREY_OFF
+	}
+
+	level := HT16K33_GREY_FULL
+	for plane := range p.dimBuffer {
+		if p.dimBuffer[plane][x]&(1<<y) != 0 {
+			level -= 1 << uint(plane)
+		}
+	}
+
+	return level
+}
+
+/*
+ * @brief Show the next bit plane of the frame last drawn. Call this
+ *        every few milliseconds, including while the frame isn't
+ *        changing, eg. from a `display.Refresher`. It may be called
+ *        from another goroutine than the one that draws, but then the
+ *        matrix and every other display on its bus must share a
+ *        `display.SharedBus`, as the chip's other commands and the
+ *        key scan use the bus too. If the calls come too far apart,
+ *        every lit pixel is shown at full intensity until they come
+ *        quickly enough again.
+ *
+ * @param now: The time of the call, from the same clock each time.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *HT16K33) Refresh(now time.Time) error {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.greyLevels < 3 || p.isBicolor {
+		return nil
+	}
+
+	p.isRefreshOnTime = !p.lastRefresh.IsZero() && now.Sub(p.lastRefresh) <= greyMaxPeriod
+	p.lastRefresh = now
+	p.greySlot = (p.greySlot + 1) % HT16K33_GREY_SLOTS
+
+	// Unchanged planes cost nothing to send
+	return p.sendFrame()
+}
+
+/*
+ * @brief Should a greyscale bit plane be shown, or plain on and off?
+ *        This goes by whether the last two `Refresh()` calls were
+ *        close enough together, and `Draw()` calls `checkRefreshes()`
+ *        first in case they have stopped since.
+ *
+ * @returns `true` for greyscale, otherwise `false`.
+ */
+func (p *HT16K33) isGreyShown() bool {
+
+	return p.greyLevels > 2 && !p.isBicolor && p.isRefreshOnTime
+}
+
+/*
+ * @brief Get the bit plane of the frame last drawn for the current
+ *        refresh slot: the lit pixels, less those too dim to show.
+ *
+ * @returns The frame.
+ */
+func (p *HT16K33) greyFrame() [8]byte {
+
+	plane := 1
+	if p.greySlot == 0 {
+		plane = 0
+	}
+
+	frame := [8]byte{}
+	for i := 0; i < 8; i++ {
+		frame[i] = p.shownBuffer[i] & ^p.shownDimBuffer[plane][i]
+	}
+
+	return frame
+}
+
+/*
+ * @brief Fall back to plain on and off if `Refresh()` hasn't been
+ *        called for a while, eg. because the refresher has stopped,
+ *        so a drawn frame isn't left showing a single bit plane.
+ *        Call with the lock held.
+ *
+ * @param now: The time, from the clock `Refresh()` is given.
+ */
+func (p *HT16K33) checkRefreshes(now time.Time) {
+
+	if now.Sub(p.lastRefresh) > greyMaxPeriod {
+		p.isRefreshOnTime = false
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"testing"
+	"time"
+	"wumpus/display"
+)
+
+/*
+ * @brief Make a greyscale matrix with a dim, a medium and a full
+ *        pixel along the bottom row, and show it.
+ *
+ * @param t: The test.
+ *
+ * @returns The matrix, its bus and its display RAM.
+ */
+func newTestGreyMatrix(t *testing.T) (*HT16K33, *FakeBus, *[16]byte) {
+
+	matrix, bus := newTestMatrix(t)
+	matrix.SetGreyscale(4)
+	matrix.PlotGrey(0, 0, HT16K33_GREY_DIM)
+	matrix.PlotGrey(1, 0, HT16K33_GREY_MEDIUM)
+	matrix.PlotGrey(2, 0, HT16K33_GREY_FULL)
+	_ = matrix.Draw()
+
+	ram := [16]byte{}
+	playBack(&ram, bus)
+	return matrix, bus, &ram
+}
+
+func TestPlotGrey(t *testing.T) {
+
+	// With fewer levels, intensities round up to one that shows
+	tests := []struct {
+		levels   uint
+		expected [4]uint
+	}{
+		{2, [4]uint{HT16K33_GREY_OFF, HT16K33_GREY_FULL, HT16K33_GREY_FULL, HT16K33_GREY_FULL}},
+		{3, [4]uint{HT16K33_GREY_OFF, HT16K33_GREY_DIM, HT16K33_GREY_DIM, HT16K33_GREY_FULL}},
+		{4, [4]uint{HT16K33_GREY_OFF, HT16K33_GREY_DIM, HT16K33_GREY_MEDIUM, HT16K33_GREY_FULL}},
+	}
+
+	for _, test := range tests {
+		matrix, _ := newTestMatrix(t)
+		matrix.SetGreyscale(test.levels)
+		for level := uint(0); level < 4; level++ {
+			matrix.PlotGrey(level, 0, level)
+			if grey := matrix.GetGrey(level, 0); grey != test.expected[level] {
+				t.Errorf("level %d with %d levels read back as %d", level, test.levels, grey)
+			}
+		}
+
+		// Plain plotting shows the pixel in full
+		matrix.Plot(1, 0, true)
+		if grey := matrix.GetGrey(1, 0); grey != HT16K33_GREY_FULL {
+			t.Errorf("plotted pixel with %d levels read back as %d", test.levels, grey)
+		}
+	}
+}
+
+func TestGreyscaleRefresh(t *testing.T) {
+
+	matrix, bus, ram := newTestGreyMatrix(t)
+
+	// Count how many refreshes light each pixel
+	start := time.Now()
+	lit := [3]int{}
+	for i := 0; i < 7; i++ {
+		if err := matrix.Refresh(start.Add(time.Duration(i) * 2 * time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+
+		playBack(ram, bus)
+
+		// The first refresh has none before it to be on time with
+		if i == 0 {
+			continue
+		}
+
+		for x := range lit {
+			if ram[x*2] != 0 {
+				lit[x] += 1
+			}
+		}
+	}
+
+	if lit != [3]int{2, 4, 6} {
+		t.Errorf("pixels lit %v times in 6 refreshes, expected [2 4 6]", lit)
+	}
+
+	// A late refresh shows every lit pixel in full
+	_ = matrix.Refresh(start.Add(time.Second))
+	playBack(ram, bus)
+	if ram[0] == 0 || ram[2] == 0 || ram[4] == 0 {
+		t.Errorf("late refresh shows % X", ram)
+	}
+}
+
+func TestGreyscaleFallsBackWhenRefreshStops(t *testing.T) {
+
+	matrix, bus, ram := newTestGreyMatrix(t)
+	start := time.Now()
+	_ = matrix.Refresh(start)
+	_ = matrix.Refresh(start.Add(2 * time.Millisecond))
+	playBack(ram, bus)
+	if ram[0] != 0 {
+		t.Fatalf("dim pixel shown in full while refreshing: % X", ram)
+	}
+
+	// Nothing has refreshed the matrix for a while, so
+	// drawing it shows every lit pixel in full
+	matrix.lastRefresh = start.Add(-time.Second)
+	_ = matrix.Draw()
+	playBack(ram, bus)
+	if ram[0] == 0 || ram[2] == 0 || ram[4] == 0 {
+		t.Errorf("stalled refresh shows % X", ram)
+	}
+}
+
+func TestGreyscaleFallsBackWhenRefresherStalls(t *testing.T) {
+
+	matrix, bus, ram := newTestGreyMatrix(t)
+	refresher := display.NewRefresher(matrix, time.Millisecond)
+	if !refresher.Start() {
+		t.Fatal("not refreshing")
+	}
+
+	// The refreshes stop, and the game draws the next frame
+	// well after the last one
+	time.Sleep(20 * time.Millisecond)
+	refresher.Stop()
+	time.Sleep(20 * time.Millisecond)
+	_ = matrix.Draw()
+
+	playBack(ram, bus)
+	if ram[0] == 0 || ram[2] == 0 || ram[4] == 0 {
+		t.Errorf("stalled refresher leaves % X", ram)
+	}
+}
+
+func TestGreyscaleNeedsRefreshing(t *testing.T) {
+
+	// Plain on and off, and bicolor matrices, don't refresh
+	matrix, bus := newTestMatrix(t)
+	matrix.SetGreyscale(2)
+	matrix.Plot(0, 0, true)
+	_ = matrix.Refresh(time.Now())
+	checkWrites(t, bus)
+
+	matrix.SetGreyscale(4)
+	matrix.SetBicolor(true)
+	matrix.PlotGrey(0, 0, HT16K33_GREY_DIM)
+	_ = matrix.Refresh(time.Now())
+	checkWrites(t, bus)
+}
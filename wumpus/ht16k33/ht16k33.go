@@ -10,8 +10,9 @@
 package ht16k33
 
 import (
-	"machine"
+	"sync"
 	"time"
+	"wumpus/display"
 	"wumpus/graphics"
 )
 
@@ -22,89 +23,135 @@ const (
 	HT16K33_CMD_SYSTEM_OFF      uint8 = 0x20
 	HT16K33_FRAME_STORE_ADDRESS uint8 = 0x00
 	HT16K33_CMD_BRIGHTNESS      uint8 = 0xE0
-	HT16K33_CMD_BLINK           uint8 = 0x81
+	HT16K33_CMD_BLINK           uint8 = 0x80
 	HT16K33_ADDRESS             uint8 = 0x70
+
+	// Hardware blink rates, for `SetBlink()`
+	HT16K33_BLINK_OFF    uint = display.BLINK_OFF
+	HT16K33_BLINK_2HZ    uint = display.BLINK_2HZ
+	HT16K33_BLINK_1HZ    uint = display.BLINK_1HZ
+	HT16K33_BLINK_HALFHZ uint = display.BLINK_HALFHZ
+
+	// Orientations, for `SetOrientation()`: how far the
+	// matrix is turned clockwise from the README's circuit
+	HT16K33_ROTATE_0   uint = display.ROTATE_0
+	HT16K33_ROTATE_90  uint = display.ROTATE_90
+	HT16K33_ROTATE_180 uint = display.ROTATE_180
+	HT16K33_ROTATE_270 uint = display.ROTATE_270
+)
+
+/*
+ * The pause between the frames of scrolling text
+ */
+var printPeriod time.Duration = time.Duration(display.SCROLL_PERIOD_MS) * time.Millisecond
+
+/*
+ * The matrix is one of the displays the game can draw on,
+ * and it can show colour and greyscale, and blink
+ */
+var (
+	_ display.ColourDisplay    = (*HT16K33)(nil)
+	_ display.GreyscaleDisplay = (*HT16K33)(nil)
+	_ display.BlinkingDisplay  = (*HT16K33)(nil)
 )
 
+/*
+ * An 8x8 LED matrix
+ */
 type HT16K33 struct {
-	// Host I2C bus
-	bus machine.I2C
-	// Internal data: I2C address, brightness level, frame buffer
-	address    uint8
-	brightness uint
-	buffer     [8]byte
+	// The chip: bus, address and display setup
+	core
+	// Frame buffer
+	buffer [8]byte
+	// Bicolor matrices: the frame buffer holds the green LEDs,
+	// and this holds the red ones
+	isBicolor bool
+	redBuffer [8]byte
+	// Greyscale: the number of intensities, how far each lit pixel
+	// is dimmed, as two bit planes, and the refresh state
+	greyLevels      uint
+	dimBuffer       [2][8]byte
+	greySlot        uint
+	lastRefresh     time.Time
+	isRefreshOnTime bool
+	// The frame as last drawn, which `Refresh()` shows plane by
+	// plane, and a lock on it and the display setup, as `Refresh()`
+	// may be called from another goroutine
+	shownBuffer    [8]byte
+	shownRedBuffer [8]byte
+	shownDimBuffer [2][8]byte
+	lock           *sync.Mutex
+	// Orientation: applied as the frame buffer is sent
+	rotation    uint
+	isMirroredX bool
+	isMirroredY bool
 }
 
 /*
  * @brief Convenience method to instantiate and initialise
  *        an HT16K33 struct.
  *
- * @param bus:     A TinyGo machine.I2C instance.
+ * @param bus:     The host I2C bus, eg. a TinyGo `*machine.I2C`.
  *                 IMPORTANT This must be configured by the calling
  *                           application BEFORE calling `init()` or any
  *                           other HT16K33 method.
  * @param address: The display's 7-bit I2C address. Defaults to `0x70`
  *                 if out of range.
+ *
+ * @returns The display, and an error if it did not respond.
  */
-func New(bus machine.I2C, address uint8) HT16K33 {
+func New(bus Bus, address uint8) (HT16K33, error) {
 
-	if address < 8 || address > 0xF0 {
-		address = HT16K33_ADDRESS
-	}
-	return HT16K33{
-		bus:        bus,
-		address:    address,
-		brightness: 15,
+	chip, err := newCore(bus, address)
+	display := HT16K33{
+		core:       chip,
 		buffer:     [8]byte{0, 0, 0, 0, 0, 0, 0, 0},
+		greyLevels: 2,
+		lock:       &sync.Mutex{},
 	}
+
+	return display, err
 }
 
 /*
  * @brief Convenience method to power on the display, set a default
  *        brightness, clear the frame buffer and write the buffer to
  *        the display.
- */
-func (p *HT16K33) Init() {
-
-	p.Power(true)
-	p.SetBrightness(8)
-	p.Clear()
-	p.Draw()
-}
-
-/*
- * @brief Turn the display on or off.
- *        The display must be turned on before it can be used
- *        (by calling `Draw()`).
  *
- * @param isOn: `true` to enable the display, `false` to turn it off.
+ * @returns An error if the display could not be reached.
  */
-func (p *HT16K33) Power(isOn bool) {
+func (p *HT16K33) Init() error {
 
-	if isOn {
-		p.i2cWriteByte(HT16K33_CMD_SYSTEM_ON)
-		p.i2cWriteByte(HT16K33_CMD_DISPLAY_ON)
-	} else {
-		p.i2cWriteByte(HT16K33_CMD_DISPLAY_OFF)
-		p.i2cWriteByte(HT16K33_CMD_SYSTEM_OFF)
+	if err := p.Power(true); err != nil {
+		return err
 	}
+
+	if err := p.SetBrightness(8); err != nil {
+		return err
+	}
+
+	p.Clear()
+	return p.Draw()
 }
 
 /*
- * @brief Set the display's brightness.
- *        The effect is immediate.
+ * @brief Set how the matrix is mounted, so that (0,0) stays at the
+ *        bottom left as the viewer sees it, and sprites and text
+ *        appear the right way up. Takes effect at the next `Draw()`.
  *
- * @param brightness: A value between 0 (dim) and 15 (very bright).
- *                    Note that 0 does not turn off the display.
+ * @param rotation:    `HT16K33_ROTATE_0`, `HT16K33_ROTATE_90`,
+ *                     `HT16K33_ROTATE_180` or `HT16K33_ROTATE_270`.
+ * @param isMirroredX: `true` to flip the image left to right.
+ * @param isMirroredY: `true` to flip the image top to bottom.
  */
-func (p *HT16K33) SetBrightness(brightness uint) {
+func (p *HT16K33) SetOrientation(rotation uint, isMirroredX bool, isMirroredY bool) {
 
-	if brightness > 15 {
-		brightness = 15
-	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
 
-	p.brightness = brightness
-	p.i2cWriteByte(HT16K33_CMD_BRIGHTNESS | byte(brightness&0xFF))
+	p.rotation = rotation & 0x03
+	p.isMirroredX = isMirroredX
+	p.isMirroredY = isMirroredY
 }
 
 /*
@@ -112,15 +159,18 @@ func (p *HT16K33) SetBrightness(brightness uint) {
  *        Doesn't update the display -- call `Draw()` to do so.
  *
  * @param sprite: A graphic stored as an [8]byte array.
+ *
+ * @returns An error if the display could not be reached.
  */
-func (p *HT16K33) DrawSprite(sprite *graphics.Sprite) {
+func (p *HT16K33) DrawSprite(sprite *graphics.Sprite) error {
 
 	// Write the sprite across the matrix
 	// NOTE Assumes the sprite is 8 pixels wide
 	p.buffer = *sprite
+	p.clearLayers()
 
 	// Send the buffer to the LED matrix
-	p.Draw()
+	return p.Draw()
 }
 
 /*
@@ -146,53 +196,51 @@ func (p *HT16K33) Plot(x uint, y uint, isSet bool) {
 	}
 
 	p.buffer[x] = col
+
+	// Plain pixels are never dimmed
+	p.dimBuffer[0][x] &= ^(1 << y)
+	p.dimBuffer[1][x] &= ^(1 << y)
 }
 
 /*
- * @brief Scroll a text string across the display.
- *        The text should only contain valid Ascii characters.
+ * @brief Is a specific pixel in the frame buffer lit?
  *
- * @param text: The string to scroll.
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `true` if the pixel is set, `false` if it's clear
+ *          or off the matrix.
  */
-func (p *HT16K33) Print(text string) {
-
-	// Get the length of the text: the number of columns it encompasses
-	length := 0
-	for i := 0; i < len(text); i++ {
-		ascii := int(text[i]) - 32
-		if ascii != 0 {
-			length += len(graphics.CHARSET[ascii]) + 1
-		} else {
-			length += 2
-		}
-	}
+func (p *HT16K33) Get(x uint, y uint) bool {
 
-	// Make the output buffer to match the required number of columns
-	src_buffer := make([]byte, length)
-	for i := 0; i < length; i++ {
-		src_buffer[i] = 0x00
+	if x > 7 || y > 7 {
+		return false
 	}
 
-	// Write each character's glyph columns into the output buffer
-	col := 0
-	for i := 0; i < len(text); i++ {
-		ascii := int(text[i]) - 32
-		if ascii == 0 {
-			// It's a space, so just add two blank columns
-			col += 2
-		} else {
-			// Get the character glyph and write it to the buffer
-			glyphWidth := len(graphics.CHARSET[ascii])
-
-			for j := 0; j < glyphWidth; j++ {
-				src_buffer[col] = graphics.CHARSET[ascii][j]
-				col += 1
-			}
-		}
+	return p.buffer[x]&(1<<y) != 0
+}
+
+/*
+ * @brief Scroll a text string across the display. Text that fits
+ *        on the display is shown in the middle, without scrolling.
+ *
+ * @param text: The string to scroll.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *HT16K33) Print(text string) error {
+
+	// Render the text as a row of columns
+	src_buffer := display.TextColumns(text)
+	length := len(src_buffer)
+	if length <= 8 {
+		src_buffer = display.CentreColumns(src_buffer, 8)
+		length = 8
 	}
 
-	// Finally, animate the line by repeatedly sending 8 columns
+	// Animate the line by repeatedly sending 8 columns
 	// of the output buffer to the matrix
+	p.clearLayers()
 	cursor := 0
 	for {
 		a := cursor
@@ -201,15 +249,20 @@ func (p *HT16K33) Print(text string) {
 			a += 1
 		}
 
-		p.Draw()
+		if err := p.Draw(); err != nil {
+			return err
+		}
+
 		cursor += 1
 		if cursor > length-8 {
 			break
 		}
 
 		// Pause between frames
-		time.Sleep(80 * time.Millisecond)
+		time.Sleep(printPeriod)
 	}
+
+	return nil
 }
 
 /*
@@ -223,25 +276,85 @@ func (p *HT16K33) Clear() {
 	for i := 0; i < 8; i++ {
 		p.buffer[i] = 0x00
 	}
+
+	p.clearLayers()
+}
+
+/*
+ * @brief Clear the frame buffer's extra layers -- the red LEDs and
+ *        the greyscale dimming -- so that the frame is plain.
+ */
+func (p *HT16K33) clearLayers() {
+
+	for i := 0; i < 8; i++ {
+		p.redBuffer[i] = 0x00
+		p.dimBuffer[0][i] = 0x00
+		p.dimBuffer[1][i] = 0x00
+	}
+}
+
+/*
+ * @brief Write the internal frame buffer to the display. Only the
+ *        display RAM bytes that have changed are sent, so there's no
+ *        cost to redrawing an unchanged frame.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *HT16K33) Draw() error {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	// Keep the frame for `Refresh()`, which may be
+	// showing it while the next one is drawn
+	p.shownBuffer = p.buffer
+	p.shownRedBuffer = p.redBuffer
+	p.shownDimBuffer = p.dimBuffer
+	p.checkRefreshes(time.Now())
+	return p.sendFrame()
 }
 
 /*
- * @brief Write the internal frame buffer to the display.
+ * @brief Write the frame as last drawn to the display.
+ *        Call with the lock held.
+ *
+ * @returns An error if the display could not be reached.
  */
-func (p *HT16K33) Draw() {
+func (p *HT16K33) sendFrame() error {
+
+	// Set up the buffer holding the display RAM contents
+	ram := [16]byte{}
+
+	// Pick this refresh slot's bit plane of a greyscale frame,
+	// and turn the frame to suit the matrix' mounting
+	frame := p.shownBuffer
+	if p.isGreyShown() {
+		frame = p.greyFrame()
+	}
 
-	// Set up the buffer holding the data to be transmitted
-	output_buffer := [17]byte{}
+	frame = display.Orient(&frame, p.rotation, p.isMirroredX, p.isMirroredY)
+
+	if p.isBicolor {
+		// Each column takes two bytes of display RAM:
+		// its green LEDs, then its red ones
+		red := display.Orient(&p.shownRedBuffer, p.rotation, p.isMirroredX, p.isMirroredY)
+		for i := 0; i < 8; i++ {
+			ram[i*2] = frame[i]
+			ram[i*2+1] = red[i]
+		}
+
+		return p.writeRam(&ram)
+	}
 
 	// Span the 8 bytes of the frame buffer
-	// across the 16 bytes of the TX buffer
+	// across the 16 bytes of display RAM
 	for i := 0; i < 8; i++ {
-		a := p.buffer[i]
-		output_buffer[i*2+1] = (a >> 1) + ((a << 7) & 0xFF)
+		a := frame[i]
+		ram[i*2] = (a >> 1) + ((a << 7) & 0xFF)
 	}
 
-	// Write out the transmit buffer
-	p.i2cWriteBlock(output_buffer[:])
+	// Write out the changes
+	return p.writeRam(&ram)
 }
 
 /*
@@ -250,14 +363,19 @@ func (p *HT16K33) Draw() {
  * @param sequence:           A slice containing all the frames in order.
  * @param frameCount:         The number of 8x8 frames in the sequence.
  * @param interstitialPeriod: The time in ms between frames.
+ *
+ * @returns An error if the display could not be reached.
  */
-func (p *HT16K33) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) {
+func (p *HT16K33) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error {
 
 	count := 0
 	for {
 		frame := graphics.Sprite{}
 		copy(frame[:], sequence[count:count+8])
-		p.DrawSprite(&frame)
+		if err := p.DrawSprite(&frame); err != nil {
+			return err
+		}
+
 		time.Sleep(time.Millisecond * time.Duration(interstitialPeriod))
 
 		count += 8
@@ -265,27 +383,6 @@ func (p *HT16K33) AnimateSequence(sequence []byte, frameCount int, interstitialP
 			break
 		}
 	}
-}
-
-/*
- * @brief Write a byte to I2C.
- *
- * @param value: The byte to write.
- */
-func (p *HT16K33) i2cWriteByte(value byte) {
-
-	// Convenience function to write a single byte to the matrix
-	data := [1]byte{value}
-	p.bus.Tx(uint16(HT16K33_ADDRESS), data[:], nil)
-}
-
-/*
- * @brief Write a series of bytes to I2C.
- *
- * @param value: A slice of the bytes to write.
- */
-func (p *HT16K33) i2cWriteBlock(data []byte) {
 
-	// Convenience function to write a 'count' bytes to the matrix
-	p.bus.Tx(uint16(HT16K33_ADDRESS), data, nil)
+	return nil
 }
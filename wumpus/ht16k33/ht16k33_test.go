@@ -0,0 +1,414 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+func init() {
+
+	// Scroll text without pausing between frames
+	printPeriod = 0
+}
+
+/*
+ * @brief Make a matrix on a FakeBus.
+ *
+ * @param t: The test.
+ *
+ * @returns The matrix and its bus.
+ */
+func newTestMatrix(t *testing.T) (*HT16K33, *FakeBus) {
+
+	bus := FakeBus{}
+	matrix, err := New(&bus, HT16K33_ADDRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &matrix, &bus
+}
+
+/*
+ * @brief Check the writes recorded on a FakeBus, then forget them.
+ *
+ * @param t:        The test.
+ * @param bus:      The bus.
+ * @param expected: The data of each write expected, in order.
+ */
+func checkWrites(t *testing.T, bus *FakeBus, expected ...[]byte) {
+
+	t.Helper()
+	written := [][]byte{}
+	for _, transaction := range bus.Transactions {
+		if transaction.Address != uint16(HT16K33_ADDRESS) {
+			t.Errorf("write to 0x%02X", transaction.Address)
+		}
+
+		written = append(written, transaction.Data)
+	}
+
+	if len(expected) == 0 {
+		expected = [][]byte{}
+	}
+
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("wrote % X, expected % X", written, expected)
+	}
+
+	bus.Reset()
+}
+
+/*
+ * @brief Make the write that fills the display RAM: the start address,
+ *        then each row's byte followed by the unused byte after it.
+ *
+ * @param rows: The eight RAM row bytes.
+ *
+ * @returns The write's data.
+ */
+func ramWrite(rows ...byte) []byte {
+
+	data := make([]byte, 17)
+	for i, row := range rows {
+		data[i*2+1] = row
+	}
+
+	return data
+}
+
+/*
+ * @brief Play the display RAM writes recorded on a FakeBus back
+ *        onto a copy of the display RAM, then forget them.
+ *
+ * @param ram: The copy of the display RAM.
+ * @param bus: The bus.
+ */
+func playBack(ram *[16]byte, bus *FakeBus) {
+
+	for _, transaction := range bus.Transactions {
+		if len(transaction.Data) > 1 {
+			copy(ram[transaction.Data[0]:], transaction.Data[1:])
+		}
+	}
+
+	bus.Reset()
+}
+
+/*
+ * @brief Add text that exercises the character set's edges
+ *        to a fuzz test's seed corpus.
+ *
+ * @param f: The fuzz test.
+ */
+func addTextSeeds(f *testing.F) {
+
+	for _, text := range []string{"", " ", "I", "HI", "HUNT THE WUMPUS", "20°", "\x00\x1F", "日本", "\xFF\xFE"} {
+		f.Add(text)
+	}
+}
+
+/*
+ * @brief Check the frame that printing text leaves on a display:
+ *        text that fits is in the middle, and longer text
+ *        scrolls until its end is shown.
+ *
+ * @param t:     The test.
+ * @param frame: The frame's columns.
+ * @param text:  The text printed.
+ */
+func checkPrinted(t *testing.T, frame []byte, text string) {
+
+	t.Helper()
+	columns := display.TextColumns(text)
+	expected := columns
+	if len(columns) <= len(frame) {
+		expected = display.CentreColumns(columns, len(frame))
+	}
+
+	if !bytes.Equal(frame, expected[len(expected)-len(frame):]) {
+		t.Errorf("%q left % X", text, frame)
+	}
+}
+
+func TestNewReportsMissingChip(t *testing.T) {
+
+	bus := FakeBus{Err: errors.New("no ack")}
+	if _, err := New(&bus, HT16K33_ADDRESS); err == nil {
+		t.Error("no error from a missing chip")
+	}
+}
+
+func TestErrorsAreReported(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+	bus.Err = errors.New("no ack")
+
+	sprite := graphics.Sprite{}
+	calls := map[string]func() error{
+		"Init":          matrix.Init,
+		"Power":         func() error { return matrix.Power(true) },
+		"SetBrightness": func() error { return matrix.SetBrightness(8) },
+		"Draw":          matrix.Draw,
+		"DrawSprite":    func() error { return matrix.DrawSprite(&sprite) },
+		"Print":         func() error { return matrix.Print("HI") },
+	}
+
+	for name, call := range calls {
+		if err := call(); err != bus.Err {
+			t.Errorf("%s: got error %v, expected %v", name, err, bus.Err)
+		}
+	}
+}
+
+func TestWritesGoToItsAddress(t *testing.T) {
+
+	bus := FakeBus{}
+	matrix, _ := New(&bus, 0x73)
+	_ = matrix.Power(true)
+	_ = matrix.Draw()
+
+	for _, transaction := range bus.Transactions {
+		if transaction.Address != 0x73 {
+			t.Errorf("write to 0x%02X, expected 0x73", transaction.Address)
+		}
+	}
+
+	// Addresses out of range fall back to the default
+	bus.Reset()
+	matrix, _ = New(&bus, 0xFF)
+	_ = matrix.Power(true)
+	checkWrites(t, &bus, []byte{0x21}, []byte{0x81})
+}
+
+/*
+ * @brief Make the RAM write for a frame with one pixel lit.
+ *
+ * @param x: The pixel's column on the matrix.
+ * @param y: The pixel's row on the matrix.
+ *
+ * @returns The write's data.
+ */
+func pixelWrite(x uint, y uint) []byte {
+
+	rows := make([]byte, 8)
+	rows[x] = byte(1<<y)>>1 | byte(1<<y)<<7
+	return ramWrite(rows...)
+}
+
+func TestPower(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	_ = matrix.Power(true)
+	checkWrites(t, bus, []byte{0x21}, []byte{0x81})
+
+	_ = matrix.Power(false)
+	checkWrites(t, bus, []byte{0x80}, []byte{0x20})
+}
+
+func TestSetBrightness(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	_ = matrix.SetBrightness(8)
+	_ = matrix.SetBrightness(0)
+	_ = matrix.SetBrightness(99)
+	checkWrites(t, bus, []byte{0xE8}, []byte{0xE0}, []byte{0xEF})
+}
+
+func TestSetBlink(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// Blinking is set while the display is off, and kept when it's on
+	_ = matrix.SetBlink(HT16K33_BLINK_2HZ)
+	_ = matrix.Power(true)
+	checkWrites(t, bus, []byte{0x82}, []byte{0x21}, []byte{0x83})
+
+	_ = matrix.SetBlink(HT16K33_BLINK_HALFHZ)
+	_ = matrix.SetBlink(99)
+	checkWrites(t, bus, []byte{0x87}, []byte{0x81})
+}
+
+func TestInit(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	_ = matrix.Init()
+	checkWrites(t, bus, []byte{0x21}, []byte{0x81}, []byte{0xE8}, ramWrite())
+}
+
+func TestDraw(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// Each column is a RAM row, with its pixels rotated one bit.
+	// All of the display RAM is sent the first time
+	matrix.Plot(2, 0, true)
+	matrix.Plot(4, 1, true)
+	matrix.Plot(4, 7, true)
+	_ = matrix.Draw()
+	checkWrites(t, bus, ramWrite(0x00, 0x00, 0x80, 0x00, 0x41))
+
+	// After that, only the run of changed bytes is sent
+	matrix.Plot(4, 7, false)
+	_ = matrix.Draw()
+	checkWrites(t, bus, []byte{0x08, 0x01})
+
+	matrix.Plot(0, 0, true)
+	matrix.Plot(4, 7, true)
+	_ = matrix.Draw()
+	checkWrites(t, bus, []byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x41})
+
+	// Nothing has changed, so nothing is sent
+	_ = matrix.Draw()
+	checkWrites(t, bus)
+}
+
+func TestGet(t *testing.T) {
+
+	matrix, _ := newTestMatrix(t)
+	matrix.Plot(3, 5, true)
+	if !matrix.Get(3, 5) {
+		t.Error("plotted pixel is clear")
+	}
+
+	if matrix.Get(5, 3) || matrix.Get(8, 5) || matrix.Get(3, 8) {
+		t.Error("pixel set that wasn't plotted")
+	}
+}
+
+func TestDrawResendsAfterError(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+	_ = matrix.Draw()
+
+	bus.Err = errors.New("no ack")
+	matrix.Plot(0, 0, true)
+	if err := matrix.Draw(); err == nil {
+		t.Fatal("no error from a failed write")
+	}
+
+	// The RAM's contents are unknown, so all of it is sent
+	bus.Err = nil
+	bus.Reset()
+	_ = matrix.Draw()
+	checkWrites(t, bus, ramWrite(0x80))
+}
+
+func TestDrawSprite(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	sprite := graphics.Sprite{0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x80}
+	_ = matrix.DrawSprite(&sprite)
+	checkWrites(t, bus, ramWrite(0x80, 0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40))
+}
+
+func TestPrint(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// "HI" is 10 columns, so it scrolls through three frames,
+	// each sent as the run of RAM that changed
+	_ = matrix.Print("HI")
+	if len(bus.Transactions) != 3 {
+		t.Fatalf("%d frames sent, expected 3", len(bus.Transactions))
+	}
+
+	first := ramWrite(0x7F, 0x08, 0x08, 0x08, 0x7F, 0x00, 0x41, 0x7F)
+	if !reflect.DeepEqual(bus.Transactions[0].Data, first) {
+		t.Errorf("first frame % X, expected % X", bus.Transactions[0].Data, first)
+	}
+
+	ram := [16]byte{}
+	playBack(&ram, bus)
+	last := [16]byte{0x08, 0, 0x08, 0, 0x7F, 0, 0x00, 0, 0x41, 0, 0x7F, 0, 0x41}
+	if ram != last {
+		t.Errorf("left showing % X, expected % X", ram, last)
+	}
+
+	// Short text is shown once, in the middle
+	_ = matrix.Print("I")
+	if len(bus.Transactions) != 1 {
+		t.Errorf("%d frames sent, expected one", len(bus.Transactions))
+	}
+
+	playBack(&ram, bus)
+	middle := [16]byte{0, 0, 0, 0, 0x41, 0, 0x7F, 0, 0x41}
+	if ram != middle {
+		t.Errorf("left showing % X, expected % X", ram, middle)
+	}
+}
+
+func FuzzPrint(f *testing.F) {
+
+	addTextSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		matrix, bus := newTestMatrix(t)
+		if err := matrix.Print(text); err != nil {
+			t.Fatal(err)
+		}
+
+		// Text that fits is shown once
+		if len(display.TextColumns(text)) <= 8 && len(bus.Transactions) > 1 {
+			t.Errorf("%q scrolled through %d frames", text, len(bus.Transactions))
+		}
+
+		checkPrinted(t, matrix.buffer[:], text)
+	})
+}
+
+func TestSetOrientation(t *testing.T) {
+
+	// Where the pixel one in from the bottom left of the image
+	// lands on the matrix itself, which has (0,0) at the bottom
+	// left as in the README's circuit
+	tests := []struct {
+		name        string
+		rotation    uint
+		isMirroredX bool
+		isMirroredY bool
+		x, y        uint
+	}{
+		{"upright", HT16K33_ROTATE_0, false, false, 1, 0},
+		{"90", HT16K33_ROTATE_90, false, false, 7, 1},
+		{"180", HT16K33_ROTATE_180, false, false, 6, 7},
+		{"270", HT16K33_ROTATE_270, false, false, 0, 6},
+		{"mirrored x", HT16K33_ROTATE_0, true, false, 6, 0},
+		{"mirrored y", HT16K33_ROTATE_0, false, true, 1, 7},
+		{"mirrored both", HT16K33_ROTATE_0, true, true, 6, 7},
+		{"90 mirrored x", HT16K33_ROTATE_90, true, false, 7, 6},
+		{"270 mirrored y", HT16K33_ROTATE_270, false, true, 7, 6},
+	}
+
+	for _, test := range tests {
+		matrix, bus := newTestMatrix(t)
+		matrix.SetOrientation(test.rotation, test.isMirroredX, test.isMirroredY)
+		matrix.Plot(1, 0, true)
+		_ = matrix.Draw()
+
+		if data := bus.Transactions[0].Data; !reflect.DeepEqual(data, pixelWrite(test.x, test.y)) {
+			t.Errorf("%s: wrote % X, expected pixel %d,%d", test.name, data, test.x, test.y)
+		}
+
+		// The frame buffer itself is untouched
+		if matrix.buffer[1] != 0x01 {
+			t.Errorf("%s: frame buffer changed", test.name)
+		}
+	}
+}
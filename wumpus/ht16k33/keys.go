@@ -0,0 +1,153 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+/*
+ * CONSTANTS
+ */
+const (
+	// ROW/INT pin setup register, and its modes for `SetRowInt()`:
+	// drive the last display row, or signal key presses, active
+	// low or high
+	HT16K33_CMD_ROW_INT      uint8 = 0xA0
+	HT16K33_ROW_INT_ROW      uint  = 0
+	HT16K33_ROW_INT_LOW      uint  = 1
+	HT16K33_ROW_INT_HIGH     uint  = 3
+	HT16K33_KEY_RAM_ADDRESS  uint8 = 0x40
+	HT16K33_KEY_RAM_SIZE     int   = 6
+	HT16K33_INT_FLAG_ADDRESS uint8 = 0x60
+
+	// 13 rows by 3 key-scan lines. Key numbers run down
+	// each key-scan line in turn: KS0's are 0-12
+	HT16K33_KEY_ROWS  uint = 13
+	HT16K33_KEY_COUNT uint = 39
+
+	// A change of keys is only reported once
+	// it has been read this many times in a row
+	HT16K33_KEY_DEBOUNCE_READS uint = 2
+)
+
+/*
+ * A key pressed or released
+ */
+type KeyEvent struct {
+	Key    uint
+	IsDown bool
+}
+
+/*
+ * @brief Set the function of the ROW/INT pin. To scan keys,
+ *        the pin must not be driving a display row; set it to
+ *        signal key presses even if the signal isn't wired up.
+ *
+ * @param mode: `HT16K33_ROW_INT_ROW`, `HT16K33_ROW_INT_LOW`
+ *              or `HT16K33_ROW_INT_HIGH`.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (c *core) SetRowInt(mode uint) error {
+
+	return c.i2cWriteByte(HT16K33_CMD_ROW_INT | byte(mode&0x03))
+}
+
+/*
+ * @brief Check the chip's INT flag, which is set when a key is
+ *        pressed, for polling when the INT pin isn't wired up.
+ *
+ * @returns `true` if a key has been pressed since the key RAM was
+ *          last read, and an error if the display could not be reached.
+ */
+func (c *core) IsKeyPending() (bool, error) {
+
+	flag := [1]byte{}
+	command := [1]byte{HT16K33_INT_FLAG_ADDRESS}
+	if err := c.bus.Tx(uint16(c.address), command[:], flag[:]); err != nil {
+		return false, err
+	}
+
+	return flag[0] != 0, nil
+}
+
+/*
+ * @brief Read which keys are down right now, without debouncing.
+ *        Reading the key RAM also clears the chip's INT flag.
+ *
+ * @returns The keys as a bit field, key 0 in bit 0, and an
+ *          error if the display could not be reached.
+ */
+func (c *core) ReadKeys() (uint64, error) {
+
+	ram := [HT16K33_KEY_RAM_SIZE]byte{}
+	command := [1]byte{HT16K33_KEY_RAM_ADDRESS}
+	if err := c.bus.Tx(uint16(c.address), command[:], ram[:]); err != nil {
+		return 0, err
+	}
+
+	// Each key-scan line has two bytes, rows 0-7 then rows 8-12
+	var keys uint64
+	for line := uint(0); line < 3; line++ {
+		rows := uint64(ram[line*2]) | uint64(ram[line*2+1]&0x1F)<<8
+		keys |= rows << (line * HT16K33_KEY_ROWS)
+	}
+
+	return keys, nil
+}
+
+/*
+ * @brief Read the keys and report the presses and releases that
+ *        have held steady since the last report. Call this
+ *        regularly, eg. every 10-20ms.
+ *
+ * @returns The key events, if any, and an error if the display
+ *          could not be reached.
+ */
+func (c *core) KeyEvents() ([]KeyEvent, error) {
+
+	keys, err := c.ReadKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the keys to settle
+	if keys != c.lastKeys {
+		c.lastKeys = keys
+		c.keyReads = 1
+	} else if c.keyReads < HT16K33_KEY_DEBOUNCE_READS {
+		c.keyReads += 1
+	}
+
+	if c.keyReads < HT16K33_KEY_DEBOUNCE_READS || keys == c.keys {
+		return nil, nil
+	}
+
+	events := []KeyEvent{}
+	changed := keys ^ c.keys
+	for key := uint(0); key < HT16K33_KEY_COUNT; key++ {
+		if changed&(1<<key) != 0 {
+			events = append(events, KeyEvent{Key: key, IsDown: keys&(1<<key) != 0})
+		}
+	}
+
+	c.keys = keys
+	return events, nil
+}
+
+/*
+ * @brief Is a key down? This is the state reported by
+ *        the most recent `KeyEvents()` call.
+ *
+ * @param key: The key number, 0-38.
+ *
+ * @returns `true` if the key is down, otherwise `false`.
+ */
+func (c *core) IsKeyDown(key uint) bool {
+
+	return key < HT16K33_KEY_COUNT && c.keys&(1<<key) != 0
+}
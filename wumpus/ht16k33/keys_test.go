@@ -0,0 +1,171 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"reflect"
+	"testing"
+)
+
+/*
+ * @brief Read key events with the key RAM holding the given keys.
+ *
+ * @param t:      The test.
+ * @param matrix: The display.
+ * @param bus:    Its bus.
+ * @param keys:   The keys down: bit 0 of byte 0 is key 0.
+ *
+ * @returns The events reported.
+ */
+func readKeyEvents(t *testing.T, matrix *HT16K33, bus *FakeBus, keys ...byte) []KeyEvent {
+
+	t.Helper()
+	bus.ReadData = make([]byte, HT16K33_KEY_RAM_SIZE)
+	copy(bus.ReadData, keys)
+	events, err := matrix.KeyEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
+
+func TestSetRowInt(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	_ = matrix.SetRowInt(HT16K33_ROW_INT_LOW)
+	_ = matrix.SetRowInt(HT16K33_ROW_INT_ROW)
+	checkWrites(t, bus, []byte{0xA1}, []byte{0xA0})
+}
+
+func TestIsKeyPending(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	for _, flag := range []byte{0x00, 0xFF} {
+		bus.ReadData = []byte{flag}
+		isPending, err := matrix.IsKeyPending()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if isPending != (flag != 0) {
+			t.Errorf("flag 0x%02X read as %t", flag, isPending)
+		}
+
+		// The flag is read from its own address
+		checkWrites(t, bus, []byte{0x60})
+	}
+}
+
+func TestReadKeys(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// Each key-scan line has two bytes: rows 0-7, then rows 8-12
+	// in the low five bits of the second. Key numbers run down
+	// KS0, then KS1, then KS2
+	tests := []struct {
+		name string
+		ram  []byte
+		keys uint64
+	}{
+		{"none", []byte{0, 0, 0, 0, 0, 0}, 0},
+		{"KS0 row 0", []byte{0x01, 0, 0, 0, 0, 0}, 1 << 0},
+		{"KS0 row 12", []byte{0, 0x10, 0, 0, 0, 0}, 1 << 12},
+		{"KS1 row 0", []byte{0, 0, 0x01, 0, 0, 0}, 1 << 13},
+		{"KS1 row 7", []byte{0, 0, 0x80, 0, 0, 0}, 1 << 20},
+		{"KS2 row 8", []byte{0, 0, 0, 0, 0, 0x01}, 1 << 34},
+		{"KS2 row 12", []byte{0, 0, 0, 0, 0, 0x10}, 1 << 38},
+		{"unused bits", []byte{0, 0xE0, 0, 0xE0, 0, 0xE0}, 0},
+		{"all", []byte{0xFF, 0x1F, 0xFF, 0x1F, 0xFF, 0x1F}, 1<<HT16K33_KEY_COUNT - 1},
+	}
+
+	for _, test := range tests {
+		bus.ReadData = test.ram
+		keys, err := matrix.ReadKeys()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if keys != test.keys {
+			t.Errorf("%s: read 0x%010X, expected 0x%010X", test.name, keys, test.keys)
+		}
+
+		checkWrites(t, bus, []byte{0x40})
+	}
+}
+
+func TestKeyEvents(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// A press is reported once it's been read twice
+	if events := readKeyEvents(t, matrix, bus, 0x04); len(events) != 0 {
+		t.Errorf("reported %v before the keys settled", events)
+	}
+
+	events := readKeyEvents(t, matrix, bus, 0x04)
+	if !reflect.DeepEqual(events, []KeyEvent{{Key: 2, IsDown: true}}) {
+		t.Errorf("got %v, expected key 2 down", events)
+	}
+
+	if !matrix.IsKeyDown(2) || matrix.IsKeyDown(3) {
+		t.Error("wrong keys down")
+	}
+
+	// Held keys aren't reported again
+	if events := readKeyEvents(t, matrix, bus, 0x04); len(events) != 0 {
+		t.Errorf("reported %v for a held key", events)
+	}
+
+	// A release is reported the same way
+	_ = readKeyEvents(t, matrix, bus)
+	events = readKeyEvents(t, matrix, bus)
+	if !reflect.DeepEqual(events, []KeyEvent{{Key: 2, IsDown: false}}) {
+		t.Errorf("got %v, expected key 2 up", events)
+	}
+
+	if matrix.IsKeyDown(2) {
+		t.Error("key 2 still down")
+	}
+}
+
+func TestKeyEventsIgnoreGlitches(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t)
+
+	// A key seen in one read only is never reported
+	for i := 0; i < 3; i++ {
+		if events := readKeyEvents(t, matrix, bus, 0, 0, 0x01); len(events) != 0 {
+			t.Errorf("reported %v for a glitch", events)
+		}
+
+		if events := readKeyEvents(t, matrix, bus); len(events) != 0 {
+			t.Errorf("reported %v after a glitch", events)
+		}
+	}
+
+	// Nor is a release seen in one read only
+	_ = readKeyEvents(t, matrix, bus, 0, 0, 0x01)
+	_ = readKeyEvents(t, matrix, bus, 0, 0, 0x01)
+	if events := readKeyEvents(t, matrix, bus); len(events) != 0 {
+		t.Errorf("reported %v for a glitch", events)
+	}
+
+	if events := readKeyEvents(t, matrix, bus, 0, 0, 0x01); len(events) != 0 {
+		t.Errorf("reported %v after a glitch", events)
+	}
+
+	if !matrix.IsKeyDown(13) {
+		t.Error("key 13 not down")
+	}
+}
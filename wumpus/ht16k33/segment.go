@@ -0,0 +1,79 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"wumpus/display"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Segment backpacks have four digits, numbered from the left
+	SEGMENT_DIGITS uint = 4
+
+	// The characters `SetDigit()` shows for 0-15
+	SEGMENT_HEX_DIGITS string = "0123456789ABCDEF"
+)
+
+/*
+ * Both segment displays can show a few characters
+ */
+var (
+	_ display.TextDisplay = (*Segment7)(nil)
+	_ display.TextDisplay = (*Segment14)(nil)
+)
+
+/*
+ * A character to show on a segment display, and
+ * whether the decimal point after it is lit
+ */
+type segmentCharacter struct {
+	character rune
+	hasDot    bool
+}
+
+/*
+ * @brief Split a string into the characters to show on a segment
+ *        display. A '.' lights the decimal point of the character
+ *        before it, so "1.23" takes three digits, not four.
+ *
+ * @param text:     The string.
+ * @param hasColon: `true` if the display has a colon for ':' to
+ *                  light, otherwise ':' is shown as a character.
+ *
+ * @returns Up to `SEGMENT_DIGITS` characters, and `true`
+ *          if the text contains a colon to light.
+ */
+func segmentText(text string, hasColon bool) ([]segmentCharacter, bool) {
+
+	characters := []segmentCharacter{}
+	isColonSet := false
+	for _, character := range text {
+		last := len(characters) - 1
+		if hasColon && character == ':' {
+			isColonSet = true
+		} else if character == '.' && last >= 0 && !characters[last].hasDot {
+			characters[last].hasDot = true
+		} else if character == '.' {
+			// A point with no character before it gets a blank digit
+			characters = append(characters, segmentCharacter{character: ' ', hasDot: true})
+		} else {
+			characters = append(characters, segmentCharacter{character: character})
+		}
+	}
+
+	if uint(len(characters)) > SEGMENT_DIGITS {
+		characters = characters[:SEGMENT_DIGITS]
+	}
+
+	return characters, isColonSet
+}
@@ -0,0 +1,214 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"wumpus/graphics"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Segment bit for the decimal points
+	SEGMENT14_DECIMAL_POINT uint16 = 0x4000
+)
+
+/*
+ * A four-character 14-segment alphanumeric display, such as
+ * Adafruit's 0.54in. quad alphanumeric backpack
+ */
+type Segment14 struct {
+	// The chip: bus, address and display setup
+	core
+	// Frame buffer: each digit's segments
+	buffer [4]uint16
+}
+
+/*
+ * @brief Convenience method to instantiate a Segment14 struct.
+ *
+ * @param bus:     The host I2C bus, eg. a TinyGo `*machine.I2C`.
+ * @param address: The display's 7-bit I2C address. Defaults to `0x70`
+ *                 if out of range.
+ *
+ * @returns The display, and an error if it did not respond.
+ */
+func NewSegment14(bus Bus, address uint8) (Segment14, error) {
+
+	chip, err := newCore(bus, address)
+	return Segment14{core: chip}, err
+}
+
+/*
+ * @brief Convenience method to power on the display, set a default
+ *        brightness and clear it.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment14) Init() error {
+
+	if err := s.Power(true); err != nil {
+		return err
+	}
+
+	if err := s.SetBrightness(8); err != nil {
+		return err
+	}
+
+	s.Clear()
+	return s.Draw()
+}
+
+/*
+ * @brief Light a digit's segments directly.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit: The digit, 0-3 from the left. Others are ignored.
+ * @param glyph: The segments, A-N in bits 0-13 and the decimal point in bit 14.
+ */
+func (s *Segment14) SetGlyph(digit uint, glyph uint16) {
+
+	if digit >= SEGMENT_DIGITS {
+		return
+	}
+
+	s.buffer[digit] = glyph
+}
+
+/*
+ * @brief Show a number from 0 to 15 on a digit, in hex. Larger
+ *        numbers are shown as `graphics.SEGMENT14_UNKNOWN`.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit:  The digit, 0-3 from the left.
+ * @param value:  The number.
+ * @param hasDot: `true` to light the digit's decimal point.
+ */
+func (s *Segment14) SetDigit(digit uint, value uint, hasDot bool) {
+
+	if value > 15 {
+		s.SetCharacter(digit, 0, hasDot)
+		return
+	}
+
+	s.SetCharacter(digit, rune(SEGMENT_HEX_DIGITS[value]), hasDot)
+}
+
+/*
+ * @brief Show a character on a digit. Characters the 14-segment
+ *        set lacks are shown as `graphics.SEGMENT14_UNKNOWN`.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit:     The digit, 0-3 from the left.
+ * @param character: The character.
+ * @param hasDot:    `true` to light the digit's decimal point.
+ */
+func (s *Segment14) SetCharacter(digit uint, character rune, hasDot bool) {
+
+	glyph := segment14Glyph(character)
+	if hasDot {
+		glyph |= SEGMENT14_DECIMAL_POINT
+	}
+
+	s.SetGlyph(digit, glyph)
+}
+
+/*
+ * @brief Turn a digit's decimal point on or off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit: The digit, 0-3 from the left.
+ * @param isSet: `true` to light the point, `false` to clear it.
+ */
+func (s *Segment14) SetDecimalPoint(digit uint, isSet bool) {
+
+	if digit >= SEGMENT_DIGITS {
+		return
+	}
+
+	if isSet {
+		s.buffer[digit] |= SEGMENT14_DECIMAL_POINT
+	} else {
+		s.buffer[digit] &= ^SEGMENT14_DECIMAL_POINT
+	}
+}
+
+/*
+ * @brief Show up to four characters, from the left, and update the
+ *        display. A '.' lights the decimal point of the character
+ *        before it, so eg. "1.234" fits. There is no colon, so ':'
+ *        takes a digit of its own.
+ *
+ * @param text: The string to show.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment14) Print(text string) error {
+
+	characters, _ := segmentText(text, false)
+	s.Clear()
+	for i, character := range characters {
+		s.SetCharacter(uint(i), character.character, character.hasDot)
+	}
+
+	return s.Draw()
+}
+
+/*
+ * @brief Clear the internal frame buffer.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (s *Segment14) Clear() {
+
+	for i := range s.buffer {
+		s.buffer[i] = 0x0000
+	}
+}
+
+/*
+ * @brief Write the internal frame buffer to the display,
+ *        sending only the digits that have changed.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment14) Draw() error {
+
+	// Each digit takes two bytes of display RAM, low byte first
+	ram := [16]byte{}
+	for i, glyph := range s.buffer {
+		ram[i*2] = byte(glyph & 0xFF)
+		ram[i*2+1] = byte(glyph >> 8)
+	}
+
+	return s.writeRam(&ram)
+}
+
+/*
+ * @brief Get the segments for a character.
+ *
+ * @param character: The character.
+ *
+ * @returns The segments, or `graphics.SEGMENT14_UNKNOWN`
+ *          if the character set doesn't include it.
+ */
+func segment14Glyph(character rune) uint16 {
+
+	// The character set keeps the degree sign in place of Ascii 127
+	if character == '°' {
+		character = 127
+	}
+
+	if character < 32 || character > 127 {
+		return graphics.SEGMENT14_UNKNOWN
+	}
+
+	return graphics.SEGMENT14_CHARSET[character-32]
+}
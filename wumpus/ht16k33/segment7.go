@@ -0,0 +1,251 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"wumpus/graphics"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Segment bits for the decimal points and the centre colon
+	SEGMENT7_DECIMAL_POINT byte = 0x80
+	SEGMENT7_COLON         byte = 0x02
+
+	// Where the colon sits among the digits in display RAM
+	SEGMENT7_COLON_POSITION uint = 2
+)
+
+/*
+ * A four-digit 7-segment display with a centre colon,
+ * such as Adafruit's 0.56in. 7-segment backpack
+ */
+type Segment7 struct {
+	// The chip: bus, address and display setup
+	core
+	// Frame buffer: one byte for each display RAM position,
+	// which are the four digits with the colon in the middle
+	buffer [5]byte
+}
+
+/*
+ * @brief Convenience method to instantiate a Segment7 struct.
+ *
+ * @param bus:     The host I2C bus, eg. a TinyGo `*machine.I2C`.
+ * @param address: The display's 7-bit I2C address. Defaults to `0x70`
+ *                 if out of range.
+ *
+ * @returns The display, and an error if it did not respond.
+ */
+func NewSegment7(bus Bus, address uint8) (Segment7, error) {
+
+	chip, err := newCore(bus, address)
+	return Segment7{core: chip}, err
+}
+
+/*
+ * @brief Convenience method to power on the display, set a default
+ *        brightness and clear it.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment7) Init() error {
+
+	if err := s.Power(true); err != nil {
+		return err
+	}
+
+	if err := s.SetBrightness(8); err != nil {
+		return err
+	}
+
+	s.Clear()
+	return s.Draw()
+}
+
+/*
+ * @brief Light a digit's segments directly.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit: The digit, 0-3 from the left. Others are ignored.
+ * @param glyph: The segments, A-G in bits 0-6 and the decimal point in bit 7.
+ */
+func (s *Segment7) SetGlyph(digit uint, glyph byte) {
+
+	if digit >= SEGMENT_DIGITS {
+		return
+	}
+
+	s.buffer[segment7Position(digit)] = glyph
+}
+
+/*
+ * @brief Show a number from 0 to 15 on a digit, in hex. Larger
+ *        numbers are shown as `graphics.SEGMENT7_UNKNOWN`.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit:  The digit, 0-3 from the left.
+ * @param value:  The number.
+ * @param hasDot: `true` to light the digit's decimal point.
+ */
+func (s *Segment7) SetDigit(digit uint, value uint, hasDot bool) {
+
+	if value > 15 {
+		s.SetCharacter(digit, 0, hasDot)
+		return
+	}
+
+	s.SetCharacter(digit, rune(SEGMENT_HEX_DIGITS[value]), hasDot)
+}
+
+/*
+ * @brief Show a character on a digit. Characters the 7-segment
+ *        set lacks are shown as `graphics.SEGMENT7_UNKNOWN`.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit:     The digit, 0-3 from the left.
+ * @param character: The character.
+ * @param hasDot:    `true` to light the digit's decimal point.
+ */
+func (s *Segment7) SetCharacter(digit uint, character rune, hasDot bool) {
+
+	glyph := segment7Glyph(character)
+	if hasDot {
+		glyph |= SEGMENT7_DECIMAL_POINT
+	}
+
+	s.SetGlyph(digit, glyph)
+}
+
+/*
+ * @brief Turn a digit's decimal point on or off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param digit: The digit, 0-3 from the left.
+ * @param isSet: `true` to light the point, `false` to clear it.
+ */
+func (s *Segment7) SetDecimalPoint(digit uint, isSet bool) {
+
+	if digit >= SEGMENT_DIGITS {
+		return
+	}
+
+	position := segment7Position(digit)
+	if isSet {
+		s.buffer[position] |= SEGMENT7_DECIMAL_POINT
+	} else {
+		s.buffer[position] &= ^SEGMENT7_DECIMAL_POINT
+	}
+}
+
+/*
+ * @brief Turn the centre colon on or off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param isSet: `true` to light the colon, `false` to clear it.
+ */
+func (s *Segment7) SetColon(isSet bool) {
+
+	if isSet {
+		s.buffer[SEGMENT7_COLON_POSITION] = SEGMENT7_COLON
+	} else {
+		s.buffer[SEGMENT7_COLON_POSITION] = 0x00
+	}
+}
+
+/*
+ * @brief Show up to four characters, from the left, and update the
+ *        display. A '.' lights the decimal point of the character
+ *        before it and a ':' lights the colon, so eg. "12:34" and
+ *        "1.234" both fit.
+ *
+ * @param text: The string to show.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment7) Print(text string) error {
+
+	characters, isColonSet := segmentText(text, true)
+	s.Clear()
+	for i, character := range characters {
+		s.SetCharacter(uint(i), character.character, character.hasDot)
+	}
+
+	s.SetColon(isColonSet)
+	return s.Draw()
+}
+
+/*
+ * @brief Clear the internal frame buffer.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (s *Segment7) Clear() {
+
+	for i := range s.buffer {
+		s.buffer[i] = 0x00
+	}
+}
+
+/*
+ * @brief Write the internal frame buffer to the display,
+ *        sending only the digits that have changed.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (s *Segment7) Draw() error {
+
+	// Each position takes the first of two bytes of display RAM
+	ram := [16]byte{}
+	for i, glyph := range s.buffer {
+		ram[i*2] = glyph
+	}
+
+	return s.writeRam(&ram)
+}
+
+/*
+ * @brief Get a digit's position in display RAM, skipping the colon.
+ *
+ * @param digit: The digit, 0-3 from the left.
+ *
+ * @returns The position.
+ */
+func segment7Position(digit uint) uint {
+
+	if digit >= SEGMENT7_COLON_POSITION {
+		return digit + 1
+	}
+
+	return digit
+}
+
+/*
+ * @brief Get the segments for a character.
+ *
+ * @param character: The character.
+ *
+ * @returns The segments, or `graphics.SEGMENT7_UNKNOWN`
+ *          if the character set doesn't include it.
+ */
+func segment7Glyph(character rune) byte {
+
+	// The character set keeps the degree sign in place of Ascii 127
+	if character == '°' {
+		character = 127
+	}
+
+	if character < 32 || character > 127 {
+		return graphics.SEGMENT7_UNKNOWN
+	}
+
+	return graphics.SEGMENT7_CHARSET[character-32]
+}
@@ -0,0 +1,178 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ht16k33
+
+import (
+	"testing"
+	"wumpus/graphics"
+)
+
+/*
+ * @brief Make a 7-segment display on a FakeBus.
+ *
+ * @param t: The test.
+ *
+ * @returns The display and its bus.
+ */
+func newTestSegment7(t *testing.T) (*Segment7, *FakeBus) {
+
+	bus := FakeBus{}
+	segment, err := NewSegment7(&bus, HT16K33_ADDRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &segment, &bus
+}
+
+/*
+ * @brief Make a 14-segment display on a FakeBus.
+ *
+ * @param t: The test.
+ *
+ * @returns The display and its bus.
+ */
+func newTestSegment14(t *testing.T) (*Segment14, *FakeBus) {
+
+	bus := FakeBus{}
+	segment, err := NewSegment14(&bus, HT16K33_ADDRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &segment, &bus
+}
+
+/*
+ * @brief Make the RAM write for a 14-segment display.
+ *
+ * @param glyphs: Each digit's segments.
+ *
+ * @returns The write: the RAM address, then each
+ *          digit's low byte and high byte.
+ */
+func glyphWrite(glyphs ...uint16) []byte {
+
+	data := make([]byte, 17)
+	for i, glyph := range glyphs {
+		data[i*2+1] = byte(glyph & 0xFF)
+		data[i*2+2] = byte(glyph >> 8)
+	}
+
+	return data
+}
+
+func TestSegment7Print(t *testing.T) {
+
+	// The colon sits between the second and third digits
+	tests := []struct {
+		text     string
+		expected []byte
+	}{
+		{"12:34", ramWrite(0x06, 0x5B, 0x02, 0x4F, 0x66)},
+		{"1.234", ramWrite(0x86, 0x5B, 0x00, 0x4F, 0x66)},
+		{".5", ramWrite(0x80, 0x6D)},
+		{"12345", ramWrite(0x06, 0x5B, 0x00, 0x4F, 0x66)},
+		{"0☃", ramWrite(0x3F, graphics.SEGMENT7_UNKNOWN)},
+		{"", ramWrite()},
+	}
+
+	for _, test := range tests {
+		segment, bus := newTestSegment7(t)
+		_ = segment.Print(test.text)
+		checkWrites(t, bus, test.expected)
+	}
+}
+
+func TestSegment7SetDigit(t *testing.T) {
+
+	// Numbers past 15 have no digit
+	segment, bus := newTestSegment7(t)
+	segment.SetDigit(0, 10, false)
+	segment.SetDigit(3, 16, true)
+	segment.SetColon(true)
+	_ = segment.Draw()
+	checkWrites(t, bus, ramWrite(0x77, 0x00, 0x02, 0x00, graphics.SEGMENT7_UNKNOWN|SEGMENT7_DECIMAL_POINT))
+
+	// Only the changed run, from the colon to the last digit, is sent
+	segment.SetDecimalPoint(3, false)
+	segment.SetColon(false)
+	_ = segment.Draw()
+	checkWrites(t, bus, []byte{0x04, 0x00, 0x00, 0x00, 0x00, graphics.SEGMENT7_UNKNOWN})
+}
+
+func TestSegment14Print(t *testing.T) {
+
+	glyph := func(character rune) uint16 {
+		return graphics.SEGMENT14_CHARSET[character-32]
+	}
+
+	// There's no colon, so ':' is a character
+	tests := []struct {
+		text     string
+		expected []byte
+	}{
+		{"WUMP", glyphWrite(glyph('W'), glyph('U'), glyph('M'), glyph('P'))},
+		{"A.B", glyphWrite(glyph('A')|SEGMENT14_DECIMAL_POINT, glyph('B'))},
+		{"1:2", glyphWrite(glyph('1'), glyph(':'), glyph('2'))},
+		{"\x01", glyphWrite(graphics.SEGMENT14_UNKNOWN)},
+	}
+
+	for _, test := range tests {
+		segment, bus := newTestSegment14(t)
+		_ = segment.Print(test.text)
+		checkWrites(t, bus, test.expected)
+	}
+}
+
+func FuzzSegmentPrint(f *testing.F) {
+
+	for _, text := range []string{"", "12:34", "1.234", "....", "W 12", "°C", "ab:cd.ef", "\xFF☃:"} {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		segment7, bus7 := newTestSegment7(t)
+		segment14, bus14 := newTestSegment14(t)
+		if err := segment7.Print(text); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := segment14.Print(text); err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the RAM bytes for the four digits and the colon are used
+		if len(bus7.Transactions) != 1 || len(bus14.Transactions) != 1 {
+			t.Fatalf("%q sent %d and %d writes", text, len(bus7.Transactions), len(bus14.Transactions))
+		}
+
+		for i, value := range bus7.Transactions[0].Data {
+			if value != 0x00 && (i == 0 || i%2 == 0 || i > 9) {
+				t.Errorf("%q set 7-segment RAM byte %d", text, i-1)
+			}
+		}
+
+		for i, value := range bus14.Transactions[0].Data {
+			if value != 0x00 && (i == 0 || i > 8) {
+				t.Errorf("%q set 14-segment RAM byte %d", text, i-1)
+			}
+		}
+
+		// Showing the same text again changes nothing, so sends nothing
+		bus7.Reset()
+		bus14.Reset()
+		_ = segment7.Print(text)
+		_ = segment14.Print(text)
+		if len(bus7.Transactions) != 0 || len(bus14.Transactions) != 0 {
+			t.Errorf("%q sent again", text)
+		}
+	})
+}
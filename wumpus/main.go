@@ -11,9 +11,11 @@ package main
 
 import (
 	"fmt"
-	"machine"
-	rnd "math/rand"
+	"strconv"
+	"strings"
 	"time"
+	"wumpus/display"
+	"wumpus/engine"
 	"wumpus/graphics"
 	"wumpus/ht16k33"
 )
@@ -21,326 +23,793 @@ import (
 func main() {
 
 	// Set up the hardware or fail out
-	if !setup() {
-		failLoop()
+	if failure := setup(); failure != FAIL_NONE {
+		failLoop(failure)
+	}
+
+	// FROM 1.1.0
+	// Keep a greyscale matrix refreshed in the background,
+	// however the game waits
+	if greyMatrix, isGreyscale := matrix.(display.GreyscaleDisplay); isGreyscale {
+		refresher = display.NewRefresher(greyMatrix, time.Duration(GREY_REFRESH_PERIOD_MS)*time.Millisecond)
+		if !refresher.Start() {
+			refresher = nil
+		}
 	}
 
 	// FROM 1.0.1
 	// Splash screen
-	matrix.Print(textIntro)
+	showText(textIntro)
 
 	// FROM 1.0.1
 	// Initiate session
 	gamesWon = 0
 	gamesLost = 0
 
+	// FROM 1.1.0
+	// Pick the cave to play in, and how hard to make it
+	chooseCave()
+	chooseLevel()
+
 	// Play the game
 	for {
+		// FROM 1.1.0
+		// Check for a game sent to be replayed, or
+		// hold Fire as a round begins to enter a game code
+		replay = nextReplay()
+		if replay == nil && isButtonPressed() {
+			enterCode()
+		}
+
 		// Set up a new round...
 		playIntro()
 
 		// ...set up the environment...
-		createWorld()
+		if replay != nil {
+			// Play back the recording's rolls
+			gameSeed = replay.Seed()
+			recorder = nil
+			showText("    Replay " + gameCode(replay.Topology(), replay.Rules(), gameSeed) + "    ")
+			world = engine.New(replay.Topology(), replay)
+			world.Rules = replay.Rules()
+		} else {
+			// Record the game's rolls as they are made
+			var topology engine.Topology
+			var gameRules engine.Rules
+			gameSeed, topology, gameRules = nextGame()
+			recorder = engine.NewRecorder(gameSeed, topology.Name(), gameRules, engine.NewRandom(gameSeed))
+			world = engine.New(topology, recorder)
+			world.Rules = gameRules
+		}
+
+		gameStart = time.Now()
+		world.Create()
 		drawWorld()
 		_ = checkSenses(false)
 
 		// ...and start play
 		gameLoop()
+
+		// FROM 1.1.0
+		// Make the game available for replay
+		if replay == nil {
+			saveRecording(recorder.Recording.String())
+		}
 	}
+}
+
+/*
+ * @brief The main game event loop.
+ */
+func gameLoop() {
+
+	// Set run variables
+	debounceButtonFlag = false
+	isAiming = false
+	arrowPath = arrowPath[:0]
+	batSqueaked := false
 
-	return
+	for {
+		// Get the player's move, if they made one
+		direction, isFiring := readInput()
+
+		isTurnTaken := false
+		if direction != engine.NONE && isAiming {
+			// FROM 1.1.0
+			// The player is plotting the arrow's path
+			if uint(len(arrowPath)) < world.ArrowRange {
+				arrowPath = append(arrowPath, direction)
+			}
+		} else if direction != engine.NONE {
+			// Move the player and check the new location
+			// for sense information and hazards
+			moved, outcome := world.Move(direction)
+			if moved {
+				batSqueaked = false
+			}
+
+			presentOutcome(outcome)
+			isTurnTaken = moved
+		} else if isFiring && !isAiming {
+			// FROM 1.1.0
+			// Draw the bow: the player can now plot the arrow's path
+			isAiming = true
+			arrowPath = arrowPath[:0]
+			showArrowCount()
+		} else if isFiring {
+			// FROM 1.1.0
+			// Loose the arrow. With no path plotted, it flies straight
+			// on along a grid, but a tunnel must be picked. Ask the world
+			// rather than the menu: a replay may be in another cave
+			isAiming = false
+			path := arrowPath
+			if _, isGrid := world.Topology.(engine.Grid); isGrid && len(path) == 0 {
+				path = world.StraightPath(world.LastMoveDirection)
+			}
+
+			if len(path) > 0 {
+				// Shoot arrow
+				fireArrowAnimation()
+
+				// Did the arrow hit or miss?
+				outcome := world.Fire(path)
+				presentOutcome(outcome)
+				isTurnTaken = outcome != engine.SAFE
+			}
+		}
+
+		// FROM 1.1.0
+		// Once the player has acted, an awake Wumpus may move
+		if isTurnTaken && world.IsInPlay {
+			presentOutcome(world.WumpusTurn())
+		}
+
+		if !world.IsInPlay {
+			break
+		} else {
+			// Draw the world then check for smells and hazards
+			drawWorld()
+			batSqueaked = checkSenses(batSqueaked)
+
+			// FROM 1.1.0
+			// Keep the stats display and status panel up to date
+			updateStats()
+			updateStatus()
+
+			// Pause between cycles
+			sleep(50)
+		}
+	}
+
+	// FROM 1.1.0
+	// Stop the clock
+	gameLength = time.Since(gameStart)
+
+	// FROM 1.0.1
+	// Display session state, unless the stats display
+	// or the status panel shows it
+	_, hasPanel := matrix.(display.StatusDisplay)
+	if stats == nil && !hasPanel {
+		report := fmt.Sprintf("    Games won: %d, lost: %d    ", gamesWon, gamesLost)
+		showText(report)
+	}
 }
 
 /*
- * @brief Set up the game hardware.
+ * @brief Get the player's next action: from the joystick and Fire
+ *        button, or from the recording if a game is being replayed.
+ *        Live actions are recorded.
  *
- * @returns `true` if the hardware was configured, otherwise `false`
+ * @returns The direction the player chose, or `engine.NONE`, and
+ *          `true` if they fired an arrow.
  */
-func setup() bool {
+func readInput() (uint, bool) {
 
-	// Configure the I2C bus
-	i2c := machine.I2C0
-	err := i2c.Configure(machine.I2CConfig{SCL: PIN_SCL, SDA: PIN_SDA})
-	if err != nil {
-		return false
+	elapsed := uint32(time.Since(gameStart).Milliseconds())
+
+	// FROM 1.1.0
+	// Play back a recorded game until its inputs run out
+	if replay != nil && !replay.IsDone() {
+		event, ok := replay.Next(elapsed)
+		if !ok {
+			return engine.NONE, false
+		}
+
+		if event.Kind == engine.EVENT_FIRE {
+			return engine.NONE, true
+		}
+
+		return uint(event.Value), false
 	}
 
-	// Set up the LED matrix
-	matrix = ht16k33.New(*i2c, ht16k33.HT16K33_ADDRESS)
-	matrix.Init()
-	matrix.SetBrightness(4)
+	// Read joystick analog output
+	x, y := readJoystick()
 
-	// Set up sense indicator output pins:
-	// Green is the Wumpus nearby indicator
-	PIN_GREEN.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	PIN_GREEN.Low()
+	if checkJoystick(x, y) {
+		// The joystick is pointing in a direction,
+		// so get the direction the player has chosen
+		direction := getDirection(x, y)
+		if direction != engine.NONE && recorder != nil {
+			recorder.Input(elapsed, engine.EVENT_MOVE, uint32(direction))
+		}
 
-	// Red is the Pit nearby indicator
-	PIN_RED.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	PIN_RED.Low()
+		return direction, false
+	}
 
-	// Set up the speaker
-	PIN_SPEAKER.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	PIN_SPEAKER.Low()
+	// Joystick is in deadzone so can fire
+	if isButtonPressed() {
+		if !debounceButtonFlag {
+			// Set debounce timer
+			debounceButtonCount = time.Now()
+			debounceButtonFlag = true
+		} else if time.Since(debounceButtonCount).Milliseconds() > DEBOUNCE_TIME_MS {
+			// Clear debounce timer
+			debounceButtonFlag = false
+			if recorder != nil {
+				recorder.Input(elapsed, engine.EVENT_FIRE, 0)
+			}
 
-	// Set up the Fire button
-	PIN_BUTTON.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
+			return engine.NONE, true
+		}
+	}
 
-	// Set up the X- and Y-axis joystick input
-	machine.InitADC()
-	err = PIN_X.Configure(machine.ADCConfig{})
-	if err != nil {
-		return false
+	return engine.NONE, false
+}
+
+/*
+ * @brief Check for a recorded game to play back.
+ *
+ * @returns The replay, or `nil` if there is none.
+ */
+func nextReplay() *engine.Replay {
+
+	text, ok := loadRecording()
+	if !ok {
+		return nil
 	}
-	err = PIN_Y.Configure(machine.ADCConfig{})
+
+	recording, err := engine.ParseRecording(text)
 	if err != nil {
-		return false
+		return nil
 	}
 
-	// Wait 2s to stabilise
-	sleep(2000)
-	return true
+	return engine.NewReplay(recording)
 }
 
 /*
- * @brief Roll a new board.
+ * @brief Let the player choose the cave: the 8x8 grid or Gregory
+ *        Yob's dodecahedron. Left and right switch between them,
+ *        and Fire confirms the choice.
  */
-func createWorld() {
+func chooseCave() {
 
-	// The player starts at (0,0)
-	startPoints := [8]uint{0, 0, 0, 7, 7, 7, 7, 0}
-	startCorner := randomInt(0, 4) << 1
-	playerX = startPoints[startCorner]
-	playerY = startPoints[startCorner+1]
-	// Set the incoming direction
-	if playerY == 0 {
-		lastMoveDirection = UP
-	} else {
-		lastMoveDirection = DOWN
+	waitForButtonRelease()
+	for {
+		if isCaveMode {
+			matrix.DrawSprite(&graphics.CAVE_MODE)
+		} else {
+			matrix.DrawSprite(&graphics.GRID_MODE)
+		}
+
+		x, y := readJoystick()
+		if checkJoystick(x, y) {
+			direction := getDirection(x, y)
+			if direction == engine.LEFT || direction == engine.RIGHT {
+				isCaveMode = !isCaveMode
+			}
+		} else if isButtonPressed() {
+			waitForButtonRelease()
+			break
+		}
+
+		sleep(50)
 	}
 
-	// Initialise the world arrays
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			hazards[i][j] = EMPTY
-			visited[i][j] = false
-			stinkLayer[i][j] = false
-			draughtLayer[i][j] = false
-			soundLayer[i][j] = false
+	matrix.Clear()
+	matrix.Draw()
+}
+
+/*
+ * @brief Get the layout of the cave the player chose.
+ *
+ * @returns The layout.
+ */
+func cave() engine.Topology {
+
+	if isCaveMode {
+		return engine.Dodecahedron{}
+	}
+
+	return engine.Grid{}
+}
+
+/*
+ * @brief Let the player choose how hard to make the game, starting
+ *        from the level they chose last time. Left and right switch
+ *        between Easy, Normal, Hard and Custom, and Fire confirms the
+ *        choice. Custom goes on to let the player set the rules.
+ *        The choice is saved for the next session.
+ */
+func chooseLevel() {
+
+	saved, _ := loadSettings()
+	if !applySettings(saved) {
+		saved = ""
+	}
+
+	waitForButtonRelease()
+	for {
+		drawCharacter(LEVEL_NAMES[level], int(level*2+1))
+
+		x, y := readJoystick()
+		if checkJoystick(x, y) {
+			switch getDirection(x, y) {
+			case engine.LEFT:
+				level = (level + LEVEL_CUSTOM) % (LEVEL_CUSTOM + 1)
+			case engine.RIGHT:
+				level = (level + 1) % (LEVEL_CUSTOM + 1)
+			}
+		} else if isButtonPressed() {
+			waitForButtonRelease()
+			break
 		}
+
+		sleep(50)
 	}
 
-	// Create 1-3 bats
-	rollHazards(BAT, randomInt(1, 4))
+	if level == LEVEL_CUSTOM {
+		editRules()
+	}
 
-	// Create 1-3 pits
-	rollHazards(PIT, randomInt(1, 4))
+	// Only write the settings if they have changed, to spare the flash
+	if settings := settingsText(); settings != saved {
+		saveSettings(settings)
+	}
 
-	// Create one wumpus
-	// NOTE It's generated last so bats and pits
-	//      can't overwrite it by chance
-	rollHazards(WUMPUS, 1)
+	matrix.Clear()
+	matrix.Draw()
+}
 
-	// Generate sense data for sounds and LED reactions
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			if hazards[i][j] == WUMPUS {
-				if i < 7 {
-					stinkLayer[i+1][j] = true
-				}
-				if i > 0 {
-					stinkLayer[i-1][j] = true
-				}
-				if j < 7 {
-					stinkLayer[i][j+1] = true
-				}
-				if j > 0 {
-					stinkLayer[i][j-1] = true
-				}
-			} else if hazards[i][j] == PIT {
-				if i < 7 {
-					draughtLayer[i+1][j] = true
-				}
-				if i > 0 {
-					draughtLayer[i-1][j] = true
-				}
-				if j < 7 {
-					draughtLayer[i][j+1] = true
-				}
-				if j > 0 {
-					draughtLayer[i][j-1] = true
-				}
-			} else if hazards[i][j] == BAT {
-				if i < 7 {
-					soundLayer[i+1][j] = true
+/*
+ * @brief Let the player set the custom rules, one at a time. Left
+ *        and right select a rule, whose initial is shown briefly,
+ *        up and down change its value, and Fire confirms the rules.
+ */
+func editRules() {
+
+	values := customValues(customRules)
+	lowest, highest := customLimits()
+
+	cursor := 0
+	isCursorMoved := true
+	waitForButtonRelease()
+	for {
+		// Name the rule when it's selected, then show its value
+		if isCursorMoved {
+			drawCharacter(CUSTOM_SETTINGS[cursor], cursor)
+			sleep(ARROW_COUNT_PERIOD_MS)
+			isCursorMoved = false
+		}
+
+		drawCharacter(byte('0'+values[cursor]), cursor)
+
+		x, y := readJoystick()
+		if checkJoystick(x, y) {
+			switch getDirection(x, y) {
+			case engine.UP:
+				if values[cursor] < highest[cursor] {
+					values[cursor] += 1
 				}
-				if i > 0 {
-					soundLayer[i-1][j] = true
+			case engine.DOWN:
+				if values[cursor] > lowest[cursor] {
+					values[cursor] -= 1
 				}
-				if j < 7 {
-					soundLayer[i][j+1] = true
+			case engine.LEFT:
+				if cursor > 0 {
+					cursor -= 1
+					isCursorMoved = true
 				}
-				if j > 0 {
-					soundLayer[i][j-1] = true
+			case engine.RIGHT:
+				if cursor < len(values)-1 {
+					cursor += 1
+					isCursorMoved = true
 				}
 			}
+		} else if isButtonPressed() {
+			waitForButtonRelease()
+			break
 		}
+
+		sleep(50)
+	}
+
+	customRules = customRulesFrom(values)
+}
+
+/*
+ * @brief Get the value of each custom rule, in `CUSTOM_SETTINGS` order.
+ *
+ * @param gameRules: The rules.
+ *
+ * @returns The values.
+ */
+func customValues(gameRules engine.Rules) []uint {
+
+	values := []uint{gameRules.MaxBats, gameRules.MaxPits, gameRules.Arrows, 0, 0, gameRules.BatMoveChance}
+	if gameRules.IsWumpusRoaming {
+		values[3] = 1
 	}
+
+	if gameRules.AreSensesKept {
+		values[4] = 1
+	}
+
+	return values
 }
 
 /*
- * @brief Locate a hazard on the board.
+ * @brief Get the range of values each custom rule can take.
  *
- * @param hazardType: The hazard to place.
- * @param count:      The number to place.
+ * @returns The lowest and highest value of each rule.
  */
-func rollHazards(hazardType uint8, count uint) {
+func customLimits() ([]uint, []uint) {
 
-	var hazardX uint = 0
-	var hazardY uint = 0
-	var i uint
-	for i = 0; i < count; i++ {
-		for {
-			// Make sure the rolled square is empty
-			hazardX = randomInt(0, 8)
-			hazardY = randomInt(0, 8)
-			if hazards[hazardX][hazardY] == EMPTY && hazardX != playerX && hazardY != playerY {
-				break
+	lowest := []uint{0, 0, 1, 0, 0, 0}
+	highest := []uint{engine.MAX_BATS, engine.MAX_PITS, engine.MAX_ARROWS, 1, 1, engine.BAT_MOVE_ROLL}
+	return lowest, highest
+}
+
+/*
+ * @brief Make custom rules from the value of each rule.
+ *
+ * @param values: The values, in `CUSTOM_SETTINGS` order.
+ *
+ * @returns The rules.
+ */
+func customRulesFrom(values []uint) engine.Rules {
+
+	// Custom boards have a fixed number of each hazard
+	return engine.Rules{
+		MinBats:         values[0],
+		MaxBats:         values[0],
+		MinPits:         values[1],
+		MaxPits:         values[1],
+		Arrows:          values[2],
+		IsWumpusRoaming: values[3] == 1,
+		AreSensesKept:   values[4] == 1,
+		BatMoveChance:   values[5],
+	}
+}
+
+/*
+ * @brief Get the level a set of rules belong to.
+ *
+ * @param gameRules: The rules.
+ *
+ * @returns The level: `LEVEL_CUSTOM` if the rules match no other.
+ */
+func levelOf(gameRules engine.Rules) uint {
+
+	switch gameRules {
+	case engine.EASY_RULES:
+		return LEVEL_EASY
+	case engine.NORMAL_RULES:
+		return LEVEL_NORMAL
+	case engine.HARD_RULES:
+		return LEVEL_HARD
+	}
+
+	return LEVEL_CUSTOM
+}
+
+/*
+ * @brief Get the rules for the level the player chose.
+ *
+ * @returns The rules.
+ */
+func rules() engine.Rules {
+
+	switch level {
+	case LEVEL_EASY:
+		return engine.EASY_RULES
+	case LEVEL_HARD:
+		return engine.HARD_RULES
+	case LEVEL_CUSTOM:
+		return customRules
+	}
+
+	return engine.NORMAL_RULES
+}
+
+/*
+ * @brief Write out the player's settings: the level number
+ *        followed by the custom rules.
+ *
+ * @returns The settings as text.
+ */
+func settingsText() string {
+
+	return fmt.Sprintf("%d %s", level, customRules.String())
+}
+
+/*
+ * @brief Restore settings written by `settingsText()`.
+ *
+ * @param text: The settings text.
+ *
+ * @returns `true` if the settings were restored, otherwise `false`.
+ */
+func applySettings(text string) bool {
+
+	savedLevel, savedRules, ok := strings.Cut(strings.TrimSpace(text), " ")
+	if !ok || len(savedLevel) != 1 || savedLevel[0] < '0' || uint(savedLevel[0]-'0') > LEVEL_CUSTOM {
+		return false
+	}
+
+	parsedRules, err := engine.ParseRules(savedRules)
+	if err != nil {
+		return false
+	}
+
+	level = uint(savedLevel[0] - '0')
+	customRules = parsedRules
+	return true
+}
+
+/*
+ * @brief Get the seed, cave and rules for the next board: the ones
+ *        the player entered a code for, if they did, otherwise a new
+ *        seed with the cave and level the player chose.
+ *
+ * @returns The seed, the cave's layout and the rules.
+ */
+func nextGame() (uint32, engine.Topology, engine.Rules) {
+
+	if isSeedRequested {
+		isSeedRequested = false
+		return requestedSeed, requestedCave, requestedRules
+	}
+
+	return randomSeed(), cave(), rules()
+}
+
+/*
+ * @brief Write out a game's code, from which it can be rolled again:
+ *        the cave's initial and the level's initial, then for the
+ *        custom level a digit for each rule, then a space and the
+ *        seed as eight hex digits, eg. `GN 1A2B3C4D`.
+ *
+ * @param topology:  The cave's layout.
+ * @param gameRules: The rules.
+ * @param seed:      The seed.
+ *
+ * @returns The code.
+ */
+func gameCode(topology engine.Topology, gameRules engine.Rules, seed uint32) string {
+
+	code := []byte{topology.Name()[0], LEVEL_NAMES[levelOf(gameRules)]}
+	if levelOf(gameRules) == LEVEL_CUSTOM {
+		for _, value := range customValues(gameRules) {
+			code = append(code, SEED_DIGITS[value])
+		}
+	}
+
+	return fmt.Sprintf("%s %08X", code, seed)
+}
+
+/*
+ * @brief Read a code written by `gameCode()`. Spaces are ignored.
+ *
+ * @param code: The code.
+ *
+ * @returns The seed, the cave's layout, the rules, and `true` if
+ *          the code is valid, otherwise `false`.
+ */
+func parseGameCode(code string) (uint32, engine.Topology, engine.Rules, bool) {
+
+	code = strings.ToUpper(strings.ReplaceAll(code, " ", ""))
+	if len(code) < 2 {
+		return 0, nil, engine.Rules{}, false
+	}
+
+	var topology engine.Topology
+	for _, candidate := range []engine.Topology{engine.Grid{}, engine.Dodecahedron{}} {
+		if candidate.Name()[0] == code[0] {
+			topology = candidate
+		}
+	}
+
+	gameLevel := strings.IndexByte(LEVEL_NAMES, code[1])
+	if topology == nil || gameLevel < 0 {
+		return 0, nil, engine.Rules{}, false
+	}
+
+	gameRules := []engine.Rules{engine.EASY_RULES, engine.NORMAL_RULES, engine.HARD_RULES, customRules}[gameLevel]
+	code = code[2:]
+	if uint(gameLevel) == LEVEL_CUSTOM {
+		lowest, highest := customLimits()
+		if len(code) < len(lowest) {
+			return 0, nil, engine.Rules{}, false
+		}
+
+		values := make([]uint, len(lowest))
+		for i := range values {
+			value := strings.IndexByte(SEED_DIGITS, code[i])
+			if value < int(lowest[i]) || value > int(highest[i]) {
+				return 0, nil, engine.Rules{}, false
 			}
+
+			values[i] = uint(value)
 		}
 
-		// Place the hazard
-		hazards[hazardX][hazardY] = hazardType
+		gameRules = customRulesFrom(values)
+		code = code[len(values):]
+	}
+
+	seed, err := strconv.ParseUint(code, 16, 32)
+	if len(code) != 8 || err != nil {
+		return 0, nil, engine.Rules{}, false
 	}
+
+	return uint32(seed), topology, gameRules, true
 }
 
 /*
- * @brief The main game event loop.
+ * @brief Set the next board to be rolled from a game code.
+ *
+ * @param code: The code.
+ *
+ * @returns `true` if the code is valid, otherwise `false`.
  */
-func gameLoop() {
+func requestGame(code string) bool {
+
+	seed, topology, gameRules, ok := parseGameCode(code)
+	if ok {
+		requestedSeed = seed
+		requestedCave = topology
+		requestedRules = gameRules
+		isSeedRequested = true
+	}
 
-	// Set run variables
-	isInPlay = true
-	debounceButtonFlag = false
-	batSqueaked := false
+	return ok
+}
 
-	for {
-		// Read joystick analog output
-		x := PIN_X.Get()
-		y := PIN_Y.Get()
-		isDead := false
+/*
+ * @brief Get the characters a game code can have at a position:
+ *        the cave's initials, the level's, each custom rule's
+ *        values, or the seed's hex digits.
+ *
+ * @param code:     The code, without the space.
+ * @param position: The character's position.
+ *
+ * @returns The characters.
+ */
+func codeCharacters(code []byte, position int) string {
 
-		if checkJoystick(x, y) {
-			// The joystick is pointing in a direction,
-			// so get the direction the player has chosen
-			direction := getDirection(x, y)
+	switch position {
+	case 0:
+		return CAVE_NAMES
+	case 1:
+		return LEVEL_NAMES
+	}
 
-			// Record the player's current location before the move
-			visited[playerX][playerY] = true
+	lowest, highest := customLimits()
+	if code[1] == LEVEL_NAMES[LEVEL_CUSTOM] && position < 2+len(lowest) {
+		return SEED_DIGITS[lowest[position-2] : highest[position-2]+1]
+	}
 
-			switch direction {
-			case UP:
-				if playerY < 7 {
-					playerY += 1
-					lastMoveDirection = UP
-					batSqueaked = false
-				}
-			case DOWN:
-				if playerY > 0 {
-					playerY -= 1
-					lastMoveDirection = DOWN
-					batSqueaked = false
-				}
-			case LEFT:
-				if playerX > 0 {
-					playerX -= 1
-					lastMoveDirection = LEFT
-					batSqueaked = false
+	return SEED_DIGITS
+}
+
+/*
+ * @brief Let the player dial in a game code, one character at a time,
+ *        to replay a cave. Up and down change the character, left and
+ *        right select a character, and Fire confirms the code.
+ */
+func enterCode() {
+
+	// Start from the last game's code
+	var lastCode string
+	if world.Topology != nil {
+		lastCode = gameCode(world.Topology, world.Rules, gameSeed)
+	} else {
+		lastCode = gameCode(cave(), rules(), gameSeed)
+	}
+
+	code := []byte(strings.ReplaceAll(lastCode, " ", ""))
+	cursor := 0
+	waitForButtonRelease()
+	for {
+		// Mark roughly how far along the code the character is
+		drawCharacter(code[cursor], cursor*8/len(code))
+
+		x, y := readJoystick()
+		if checkJoystick(x, y) {
+			characters := codeCharacters(code, cursor)
+			index := strings.IndexByte(characters, code[cursor])
+			switch getDirection(x, y) {
+			case engine.UP:
+				code[cursor] = characters[(index+1)%len(characters)]
+			case engine.DOWN:
+				code[cursor] = characters[(index+len(characters)-1)%len(characters)]
+			case engine.LEFT:
+				if cursor > 0 {
+					cursor -= 1
 				}
-			case RIGHT:
-				if playerX < 7 {
-					playerX += 1
-					lastMoveDirection = RIGHT
-					batSqueaked = false
+			case engine.RIGHT:
+				if cursor < len(code)-1 {
+					cursor += 1
 				}
 			}
 
-			// Check the new location for sense
-			// information and hazards
-			isDead = checkHazards()
-		} else {
-			// Joystick is in deadzone so can fire
-			if PIN_BUTTON.Get() {
-				if !debounceButtonFlag {
-					// Set debounce timer
-					debounceButtonCount = time.Now()
-					debounceButtonFlag = true
-				} else if time.Since(debounceButtonCount).Milliseconds() > DEBOUNCE_TIME_MS {
-					// Clear debounce timer
-					debounceButtonFlag = false
-
-					// Shoot arrow
-					fireArrowAnimation()
-
-					// Did the arrow hit or miss?
-					switch lastMoveDirection {
-					case UP:
-						if playerY < 7 {
-							if hazards[playerX][playerY+1] == WUMPUS {
-								deadWumpusAnimation()
-							} else {
-								arrowMissAnimation()
-							}
-							break
-						}
-					case DOWN:
-						if playerY > 0 {
-							if hazards[playerX][playerY-1] == WUMPUS {
-								deadWumpusAnimation()
-							} else {
-								arrowMissAnimation()
-							}
-							break
-						}
-					case RIGHT:
-						if playerX < 7 {
-							if hazards[playerX+1][playerY] == WUMPUS {
-								deadWumpusAnimation()
-							} else {
-								arrowMissAnimation()
-							}
-							break
-						}
-					case LEFT:
-						if playerX > 0 {
-							if hazards[playerX-1][playerY] == WUMPUS {
-								deadWumpusAnimation()
-							} else {
-								arrowMissAnimation()
-							}
-							break
-						}
-					}
+			// Custom levels carry their rules: add or drop them
+			isCustom := code[1] == LEVEL_NAMES[LEVEL_CUSTOM]
+			hasRules := len(code) > 10
+			if isCustom && !hasRules {
+				rulesCode := []byte{}
+				for _, value := range customValues(customRules) {
+					rulesCode = append(rulesCode, SEED_DIGITS[value])
 				}
+
+				code = append(code[:2], append(rulesCode, code[2:]...)...)
+			} else if !isCustom && hasRules {
+				code = append(code[:2], code[2+len(CUSTOM_SETTINGS):]...)
 			}
+		} else if isButtonPressed() {
+			waitForButtonRelease()
+			break
 		}
 
-		if isDead || !isInPlay {
-			break
-		} else {
-			// Draw the world then check for smells and hazards
-			drawWorld()
-			batSqueaked = checkSenses(batSqueaked)
+		sleep(50)
+	}
 
-			// Pause between cycles
-			sleep(50)
-		}
+	requestGame(string(code))
+	matrix.Clear()
+	matrix.Draw()
+}
+
+/*
+ * @brief Show a single character, centred and without scrolling.
+ *
+ * @param character: The Ascii character to show.
+ * @param position:  A column to mark along the bottom row, or -1 for none.
+ */
+func drawCharacter(character byte, position int) {
+
+	// Centre the glyph, less its trailing blank columns
+	sprite := graphics.Sprite{}
+	copy(sprite[:], display.CentreColumns(display.TextColumns(string(character)), len(sprite)))
+
+	// Mark the position along the bottom row
+	if position >= 0 && position < 8 {
+		sprite[position] |= 0x01
 	}
 
-	// FROM 1.0.1
-	// Display session state
-	report := fmt.Sprintf("    Games won: %d, lost: %d    ", gamesWon, gamesLost)
-	matrix.Print(report)
+	matrix.DrawSprite(&sprite)
+}
+
+/*
+ * @brief Briefly show how many arrows the player has left.
+ */
+func showArrowCount() {
+
+	if world.Arrows > 9 {
+		drawCharacter('9', -1)
+	} else {
+		drawCharacter(byte('0'+world.Arrows), -1)
+	}
+
+	sleep(ARROW_COUNT_PERIOD_MS)
+}
+
+/*
+ * @brief Block until the Fire button is released.
+ */
+func waitForButtonRelease() {
+
+	for isButtonPressed() {
+		sleep(10)
+	}
 }
 
 /*
@@ -388,44 +857,44 @@ func getDirection(x uint16, y uint16) uint {
 
 	if yInDeadZone && !xInDeadZone {
 		if x < LOWER_LIMIT {
-			return RIGHT
+			return engine.RIGHT
 		}
 
 		if x > UPPER_LIMIT {
-			return LEFT
+			return engine.LEFT
 		}
 	}
 
 	if xInDeadZone && !yInDeadZone {
 		if y < LOWER_LIMIT {
-			return DOWN
+			return engine.DOWN
 		}
 
 		if y > UPPER_LIMIT {
-			return UP
+			return engine.UP
 		}
 	}
 
 	if !xInDeadZone && !yInDeadZone {
 		if x < LOWER_LIMIT {
-			return RIGHT
+			return engine.RIGHT
 		}
 
 		if x > UPPER_LIMIT {
-			return LEFT
+			return engine.LEFT
 		}
 
 		if y < LOWER_LIMIT {
-			return DOWN
+			return engine.DOWN
 		}
 
 		if y > UPPER_LIMIT {
-			return UP
+			return engine.UP
 		}
 	}
 
 	// Just in case...
-	return NONE
+	return engine.NONE
 }
 
 /*
@@ -433,8 +902,7 @@ func getDirection(x uint16, y uint16) uint {
  */
 func clearPins() {
 
-	PIN_GREEN.Low()
-	PIN_RED.Low()
+	setLeds(false, false)
 }
 
 /*
@@ -444,15 +912,64 @@ func clearPins() {
 func drawWorld() {
 
 	matrix.Clear()
-	for i := 0; i < 8; i++ {
-		for j := 0; j < 8; j++ {
-			matrix.Plot(uint(i), uint(j), visited[i][j])
+	if _, ok := world.Topology.(engine.Grid); ok {
+		// FROM 1.1.0
+		// A full-colour display shows visited rooms in blue, or tinted
+		// by what was sensed there, and the player in white
+		isFullColour := display.IsFullColour(matrix)
+		playerColour := display.COLOUR_YELLOW
+		if isFullColour {
+			playerColour = display.COLOUR_WHITE
 		}
+
+		for room, isVisited := range world.Visited {
+			x, y := engine.GridPosition(room)
+			colour := display.COLOUR_GREEN
+			if isFullColour {
+				colour = senseColour(room)
+			}
+
+			plotCell(x, y, isVisited, colour, display.GREY_DIM)
+
+			// FROM 1.1.0
+			// On easier levels, visited rooms where something
+			// could be sensed flash against the player
+			if isVisited && world.Rules.AreSensesKept {
+				if stink, draught, sound := world.FeltIn(room); stink || draught || sound {
+					plotCell(x, y, !isPlayerPixelOn, colour, display.GREY_MEDIUM)
+				}
+			}
+		}
+
+		// Flash the player's location
+		x, y := engine.GridPosition(world.PlayerRoom)
+		plotCell(x, y, isPlayerPixelOn, playerColour, display.GREY_FULL)
+
+		// FROM 1.1.0
+		// Flash the arrow's plotted path in step with the player
+		if isAiming {
+			room := world.PlayerRoom
+			for _, direction := range arrowPath {
+				next, ok := world.Topology.Neighbour(room, direction)
+				if !ok {
+					break
+				}
+
+				room = next
+				x, y = engine.GridPosition(room)
+				plotCell(x, y, isPlayerPixelOn, display.COLOUR_RED, display.GREY_MEDIUM)
+			}
+		}
+	} else {
+		// FROM 1.1.0
+		drawRoom()
 	}
 
-	// Flash the player's location
-	matrix.Plot(playerX, playerY, isPlayerPixelOn)
-	matrix.Draw()
+	// FROM 1.1.0
+	// Stop if the display has dropped off the bus
+	if err := matrix.Draw(); err != nil {
+		failLoop(FAIL_DISPLAY)
+	}
 
 	if time.Since(lastPlayerPixelFlash).Milliseconds() > PLAYER_PIXEL_FLASH_PERIOD_MS {
 		isPlayerPixelOn = !isPlayerPixelOn
@@ -460,6 +977,148 @@ func drawWorld() {
 	}
 }
 
+/*
+ * @brief Get the colour a visited room is shown in on a full-colour
+ *        display: green if the player smelled the Wumpus there, red
+ *        if they felt a draught, yellow if they heard a bat, else blue.
+ *
+ * @param room: The room.
+ *
+ * @returns The colour, eg. `display.COLOUR_BLUE`.
+ */
+func senseColour(room int) uint {
+
+	stink, draught, sound := world.FeltIn(room)
+	switch {
+	case stink:
+		return display.COLOUR_GREEN
+	case draught:
+		return display.COLOUR_RED
+	case sound:
+		return display.COLOUR_YELLOW
+	}
+
+	return display.COLOUR_BLUE
+}
+
+/*
+ * @brief Light or clear a square of the map: in a colour on a
+ *        bicolor matrix, otherwise at an intensity, which shows
+ *        if the matrix has greyscale.
+ *
+ * @param x:      The square's X co-ordinate.
+ * @param y:      The square's Y co-ordinate.
+ * @param isOn:   `true` to light the square, `false` to clear it.
+ * @param colour: The colour, eg. `display.COLOUR_GREEN`.
+ * @param level:  The intensity, eg. `display.GREY_DIM`.
+ */
+func plotCell(x uint, y uint, isOn bool, colour uint, level uint) {
+
+	if !isOn {
+		colour = display.COLOUR_OFF
+		level = display.GREY_OFF
+	}
+
+	if display.IsBicolor(matrix) {
+		display.PlotColour(matrix, x, y, colour)
+	} else {
+		display.PlotGrey(matrix, x, y, level)
+	}
+}
+
+/*
+ * @brief Show the cave on a bicolor matrix after the player dies:
+ *        the hazards in red, the rooms the player visited in green,
+ *        and the room where they died in yellow. A full-colour display
+ *        shows the Wumpus in green, pits in red, bats in yellow, the
+ *        rooms visited in blue and the player's room in white.
+ */
+func drawDeathMap() {
+
+	isFullColour := display.IsFullColour(matrix)
+	matrix.Clear()
+	for room, hazard := range world.Hazards {
+		x, y := engine.GridPosition(room)
+		switch {
+		case hazard == engine.EMPTY && world.Visited[room]:
+			if isFullColour {
+				display.PlotColour(matrix, x, y, display.COLOUR_BLUE)
+			} else {
+				display.PlotColour(matrix, x, y, display.COLOUR_GREEN)
+			}
+		case hazard == engine.EMPTY:
+			continue
+		case !isFullColour:
+			display.PlotColour(matrix, x, y, display.COLOUR_RED)
+		case hazard == engine.WUMPUS:
+			display.PlotColour(matrix, x, y, display.COLOUR_GREEN)
+		case hazard == engine.PIT:
+			display.PlotColour(matrix, x, y, display.COLOUR_RED)
+		default:
+			display.PlotColour(matrix, x, y, display.COLOUR_YELLOW)
+		}
+	}
+
+	x, y := engine.GridPosition(world.PlayerRoom)
+	if isFullColour {
+		display.PlotColour(matrix, x, y, display.COLOUR_WHITE)
+	} else {
+		display.PlotColour(matrix, x, y, display.COLOUR_YELLOW)
+	}
+
+	matrix.Draw()
+}
+
+/*
+ * @brief Render the player's room and its three tunnels, for caves
+ *        that aren't grids. Tunnels to rooms the player has visited
+ *        are solid; others are dotted. The room flashes, or the
+ *        tunnels do if the player is picking one to shoot along.
+ */
+func drawRoom() {
+
+	for _, direction := range []uint{engine.LEFT, engine.UP, engine.RIGHT} {
+		next, ok := world.Topology.Neighbour(world.PlayerRoom, direction)
+		if !ok {
+			continue
+		}
+
+		if !isAiming || isPlayerPixelOn {
+			tunnel := ROOM_TUNNELS[direction]
+			plotBlock(tunnel[0], tunnel[1], tunnel[2], tunnel[3], !world.Visited[next])
+		}
+	}
+
+	if isAiming || isPlayerPixelOn {
+		plotBlock(ROOM_BLOCK[0], ROOM_BLOCK[1], ROOM_BLOCK[2], ROOM_BLOCK[3], false)
+	}
+
+	// Count the tunnels picked for the arrow along the bottom row
+	if isAiming {
+		plotBlock(0, 0, uint(len(arrowPath)), 1, false)
+	}
+}
+
+/*
+ * @brief Light a rectangle of pixels.
+ *
+ * @param x:        The left column.
+ * @param y:        The bottom row.
+ * @param width:    The number of columns.
+ * @param height:   The number of rows.
+ * @param isDotted: `true` to light alternate pixels only.
+ */
+func plotBlock(x uint, y uint, width uint, height uint, isDotted bool) {
+
+	for i := x; i < x+width; i++ {
+		for j := y; j < y+height; j++ {
+			if !isDotted || (i+j)%2 == 0 {
+				matrix.Plot(i, j, true)
+			}
+		}
+	}
+}
+
 /*
  * @brief Set the signal LEDs based on the player's location.
  *
@@ -469,11 +1128,11 @@ func drawWorld() {
  */
 func checkSenses(batSqueakedAlready bool) bool {
 
-	PIN_GREEN.Set(stinkLayer[playerX][playerY])
-	PIN_RED.Set(draughtLayer[playerX][playerY])
+	isStinky, isDraughty, isNoisy := world.Senses()
+	setLeds(isStinky, isDraughty)
 
 	// Play a sound to signal a nearby bat
-	if soundLayer[playerX][playerY] && !batSqueakedAlready {
+	if isNoisy && !batSqueakedAlready {
 		tone(600, 50, 50)
 		tone(500, 50, 50)
 		tone(400, 50, 50)
@@ -484,43 +1143,41 @@ func checkSenses(batSqueakedAlready bool) bool {
 }
 
 /*
- * @brief Has the player stepped on a hazard?
+ * @brief Present the result of the player's last action.
  *
- * @returns `true` if the player has hit a hazard, `false` if they are safe.
+ * @param outcome: What the game engine reported.
  */
-func checkHazards() bool {
+func presentOutcome(outcome engine.Outcome) {
 
-	if hazards[playerX][playerY] == BAT {
-		// Player encountered a bat: play the animation...
+	switch outcome {
+	case engine.GRABBED_BY_BAT:
+		// Player encountered a bat and was dropped elsewhere
 		grabbedByBatAnimation()
-
-		// ...then drop the player at random
-		var x uint
-		var y uint
-		for {
-			x = randomInt(0, 8)
-			y = randomInt(0, 8)
-			if hazards[x][y] == EMPTY {
-				break
-			}
-		}
-
-		playerX = x
-		playerY = y
-	} else if hazards[playerX][playerY] == PIT {
+	case engine.FELL_INTO_PIT:
 		// Player fell down a pit -> death
 		plungedIntoPitAnimation()
-		gameLost(false)
-		return true
-	} else if hazards[playerX][playerY] == WUMPUS {
-		// Player ran into the Wumpus -> death
+		gameLost(textFell)
+	case engine.EATEN_BY_WUMPUS:
+		// Player and Wumpus met -> death
 		wumpusWinAnimation()
-		gameLost(true)
-		return true
+		gameLost(textLose)
+	case engine.KILLED_WUMPUS:
+		deadWumpusAnimation()
+	case engine.MISSED_WUMPUS:
+		arrowMissAnimation()
+		showArrowCount()
+	case engine.SHOT_SELF:
+		// Player's arrow came back round -> death
+		tone(2000, 50, 50)
+		tone(150, 400, 0)
+		gameLost(textShot)
+	case engine.OUT_OF_ARROWS:
+		// Player's last arrow missed -> death
+		arrowMissAnimation()
+		gameLost(textNoArrows)
+	case engine.WUMPUS_MOVED:
+		wumpusMovedSound()
 	}
-
-	// Player is safe... for now!
-	return false
 }
 
 /*
@@ -674,9 +1331,19 @@ func arrowMissAnimation() {
 	matrix.Clear()
 	matrix.Draw()
 
-	// ...and then the Wumpus gets the player
-	wumpusWinAnimation()
-	gameLost(true)
+	// ...and the noise wakes the Wumpus
+	for i := 0; i < 3; i++ {
+		tone(120, 150, 100)
+	}
+}
+
+/*
+ * @brief Signal the Wumpus lumbering into another room.
+ */
+func wumpusMovedSound() {
+
+	tone(70, 150, 50)
+	tone(60, 250, 0)
 }
 
 /*
@@ -707,7 +1374,7 @@ func gameWon() {
 
 	gamesWon += 1
 	clearPins()
-	matrix.DrawSprite(&graphics.TROPHY)
+	display.DrawColourSprite(matrix, &graphics.TROPHY_COLOUR)
 	matrix.SetBrightness(randomInt(1, 15))
 	tone(1397, 100, 100)
 	matrix.SetBrightness(randomInt(7, 14))
@@ -756,8 +1423,12 @@ func gameWon() {
 		sleep(125)
 	}
 
+	// FROM 1.1.0
+	// Let the display flash the trophy by itself
 	matrix.SetBrightness(12)
+	display.SetBlink(matrix, display.BLINK_2HZ)
 	sleep(1000)
+	display.SetBlink(matrix, display.BLINK_OFF)
 	matrix.SetBrightness(2)
 
 	// Show the success message
@@ -767,16 +1438,24 @@ func gameWon() {
 /*
  * @brief Give the player a funeral.
  *
- * @param wumpusWon: `true` if the player died at the Wumpus' claws,
- *                   `false` if they fell into a pit
+ * @param text: How the player died, eg. `textLose` if at the
+ *              Wumpus' claws, `textFell` if they fell into a pit
  */
-func gameLost(wumpusWon bool) {
+func gameLost(text string) {
 
 	gamesLost += 1
 	clearPins()
 
-	// Show the player's grave
-	matrix.DrawSprite(&graphics.GRAVE)
+	// FROM 1.1.0
+	// Show what killed the player, where there's colour to do it
+	if _, ok := world.Topology.(engine.Grid); ok && display.IsBicolor(matrix) {
+		drawDeathMap()
+		sleep(DEATH_MAP_PERIOD_MS)
+	}
+
+	// Show the player's grave, slowly flashing
+	display.DrawColourSprite(matrix, &graphics.GRAVE_COLOUR)
+	display.SetBlink(matrix, display.BLINK_HALFHZ)
 	tone(294, 400, 200)
 	tone(294, 400, 200)
 	tone(294, 100, 200)
@@ -788,12 +1467,9 @@ func gameLost(wumpusWon bool) {
 	tone(294, 400, 200)
 	tone(294, 100, 200)
 	tone(294, 800, 3000)
+	display.SetBlink(matrix, display.BLINK_OFF)
 
-	if wumpusWon {
-		gameOver(textLose)
-	} else {
-		gameOver(textFell)
-	}
+	gameOver(text)
 }
 
 /*
@@ -803,12 +1479,156 @@ func gameOver(text string) {
 
 	// Show final message and
 	// clear the screen for the next game
-	isInPlay = false
-	matrix.Print(text)
+	showText(text)
+
+	// FROM 1.1.0
+	// Show the game's code so the cave can be replayed
+	showText("    Code " + gameCode(world.Topology, world.Rules, gameSeed) + "    ")
 	matrix.Clear()
 	matrix.Draw()
 }
 
+/*
+ * @brief Scroll a message across the matrix. The player can
+ *        press Fire to skip it.
+ *
+ * @param text: The message.
+ */
+func showText(text string) {
+
+	scroller := display.NewScroller(matrix, text)
+	for !scroller.IsDone() {
+		if scroller.Step(time.Now()) != nil {
+			failLoop(FAIL_DISPLAY)
+		}
+
+		if isButtonPressed() {
+			scroller.Stop()
+			waitForButtonRelease()
+		}
+
+		updateStats()
+		updateStatus()
+		sleep(10)
+	}
+}
+
+/*
+ * @brief Set up the stats display, if the game has one.
+ *
+ * @param bus:  The I2C bus the display is on.
+ * @param kind: `STATS_NONE`, `STATS_SEGMENT7` or `STATS_SEGMENT14`.
+ *
+ * @returns The display, or `nil` if there isn't one or it did not
+ *          respond -- the game then reports the stats on the matrix.
+ */
+func openStats(bus ht16k33.Bus, kind uint) display.TextDisplay {
+
+	switch kind {
+	case STATS_SEGMENT7:
+		segments, err := ht16k33.NewSegment7(bus, STATS_ADDRESS)
+		if err == nil && segments.Init() == nil {
+			return &segments
+		}
+	case STATS_SEGMENT14:
+		segments, err := ht16k33.NewSegment14(bus, STATS_ADDRESS)
+		if err == nil && segments.Init() == nil {
+			return &segments
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Show the session stats on the stats display, one at a time:
+ *        games won, games lost, arrows left and the game's time in
+ *        minutes and seconds, which stops when the game ends.
+ */
+func updateStats() {
+
+	if stats == nil {
+		return
+	}
+
+	elapsed := gameLength
+	if world.IsInPlay {
+		elapsed = time.Since(gameStart)
+	}
+
+	var text string
+	switch time.Now().UnixMilli() / STATS_PAGE_PERIOD_MS % 4 {
+	case 0:
+		text = fmt.Sprintf("W%3d", gamesWon)
+	case 1:
+		text = fmt.Sprintf("L%3d", gamesLost)
+	case 2:
+		text = fmt.Sprintf("A%3d", world.Arrows)
+	default:
+		// Stop the clock at 99.59
+		seconds := int(elapsed.Seconds())
+		if seconds > 99*60+59 {
+			seconds = 99*60 + 59
+		}
+
+		text = fmt.Sprintf("%2d.%02d", seconds/60, seconds%60)
+	}
+
+	// Only write to the display when the text changes
+	if text != statsText {
+		if stats.Print(text) != nil {
+			failLoop(FAIL_DISPLAY)
+		}
+
+		statsText = text
+	}
+}
+
+/*
+ * @brief Show the game's status beside the map, if the display has
+ *        room for it: the arrows left, the games won and lost, what
+ *        the player can sense and the game's code.
+ */
+func updateStatus() {
+
+	panel, hasPanel := matrix.(display.StatusDisplay)
+	if !hasPanel {
+		return
+	}
+
+	// Senses come before the code, which is left
+	// out if the panel is full
+	isGameSetUp := world.Topology != nil
+	lines := []string{}
+	if isGameSetUp {
+		lines = append(lines, fmt.Sprintf("Arrows %d", world.Arrows))
+	}
+
+	lines = append(lines, fmt.Sprintf("W %d  L %d", gamesWon, gamesLost))
+	if world.IsInPlay {
+		isStinky, isDraughty, isNoisy := world.Senses()
+		if isStinky {
+			lines = append(lines, "You smell a Wumpus")
+		}
+
+		if isDraughty {
+			lines = append(lines, "You feel a draught")
+		}
+
+		if isNoisy {
+			lines = append(lines, "You hear a bat")
+		}
+	}
+
+	if isGameSetUp {
+		lines = append(lines, gameCode(world.Topology, world.Rules, gameSeed))
+	}
+
+	if panel.ShowStatus(lines) != nil {
+		failLoop(FAIL_DISPLAY)
+	}
+}
+
 /*
  * @brief Present the the game's opening screen.
  */
@@ -854,75 +1674,24 @@ func playIntro() {
 }
 
 /*
- * @brief Calculate a random number.
- *
- * @param start: The baseline value.
- * @param max:   One above the highest possible roll.
- *
- * @returns: The value.
- */
-func randomInt(start uint, max uint) uint {
-
-	value, err := machine.GetRNG()
-	if err != nil {
-		return uint(rnd.Uint32()%uint32(max) + uint32(start))
-	}
-
-	return uint(uint(value)%max + start)
-}
-
-/*
- * @brief Play a sound on the piezo buzzer.
+ * @brief Sleep for the specified period of milliseconds, first
+ *        checking that the matrix is still being refreshed if
+ *        it's showing greyscale.
  *
- * @param frequency: The sound's frequency in Hz.
- * @param duration:  How long the sound plays in ms.
- * @param post:      A delay added after the sound has played.
- *
- * @returns: The value.
+ * @param period: The sleep period.
  */
-func tone(frequency uint, duration int, post uint32) {
-
-	// Get the cycle period in microseconds
-	var period float32 = 1000000.0 / float32(frequency)
-	period /= 2
-
-	// Get the microsecond timer now
-	start := time.Now()
-
-	// Loop until duration (ms) in microseconds has elapsed
-	for time.Since(start).Microseconds() < int64(duration*1000) {
-		PIN_SPEAKER.High()
-		time.Sleep(time.Duration(period) * time.Microsecond)
-		PIN_SPEAKER.Low()
-		time.Sleep(time.Duration(period) * time.Microsecond)
-	}
-
-	// Apply a post-tone delay
-	if post != 0 {
-		sleep(post)
-	}
-}
+func sleep(period uint32) {
 
-/*
- * @brief Flash the Pico led continuously to signal
- *        hardware setup failure.
- */
-func failLoop() {
+	// FROM 1.1.0
+	if refresher != nil && refresher.Err() != nil {
+		// Don't try again while reporting the failure
+		refresher = nil
+		if greyMatrix, isGreyscale := matrix.(display.GreyscaleDisplay); isGreyscale {
+			greyMatrix.SetGreyscale(2)
+		}
 
-	led := machine.LED
-	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	for {
-		led.Set(!led.Get())
-		sleep(100)
+		failLoop(FAIL_DISPLAY)
 	}
-}
-
-/*
- * @brief Sleep for the specified period of milliseconds.
- *
- * @param period: The sleep period.
- */
-func sleep(period uint32) {
 
 	time.Sleep(time.Duration(period) * time.Millisecond)
 }
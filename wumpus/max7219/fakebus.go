@@ -0,0 +1,80 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package max7219
+
+import (
+	"errors"
+)
+
+/*
+ * A Bus and chip select Pin in one, which records every transfer
+ * made while chip select is low rather than sending it, so the bytes
+ * the driver generates can be checked off-device
+ */
+type FakeBus struct {
+	Transactions [][]byte
+	// Set to make every transfer fail
+	Err error
+	// Is chip select low?
+	IsSelected bool
+}
+
+/*
+ * @brief Record a transfer. Reads are answered with zeros.
+ *
+ * @param w: The bytes to write.
+ * @param r: The buffer for any bytes read back.
+ *
+ * @returns `Err` if set, or an error if chip select isn't low.
+ */
+func (b *FakeBus) Tx(w, r []byte) error {
+
+	if b.Err != nil {
+		return b.Err
+	}
+
+	if !b.IsSelected {
+		return errors.New("chip select is high")
+	}
+
+	for i := range r {
+		r[i] = 0
+	}
+
+	// Take a copy: the driver reuses its buffers
+	data := make([]byte, len(w))
+	copy(data, w)
+	b.Transactions = append(b.Transactions, data)
+	return nil
+}
+
+/*
+ * @brief Take chip select high, ending the transfer.
+ */
+func (b *FakeBus) High() {
+
+	b.IsSelected = false
+}
+
+/*
+ * @brief Take chip select low, starting a transfer.
+ */
+func (b *FakeBus) Low() {
+
+	b.IsSelected = true
+}
+
+/*
+ * @brief Discard all recorded transfers.
+ */
+func (b *FakeBus) Reset() {
+
+	b.Transactions = nil
+}
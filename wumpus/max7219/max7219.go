@@ -0,0 +1,437 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package max7219
+
+import (
+	"time"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+const (
+	MAX7219_REG_NOOP         uint8 = 0x00
+	MAX7219_REG_DIGIT_0      uint8 = 0x01
+	MAX7219_REG_DECODE_MODE  uint8 = 0x09
+	MAX7219_REG_INTENSITY    uint8 = 0x0A
+	MAX7219_REG_SCAN_LIMIT   uint8 = 0x0B
+	MAX7219_REG_SHUTDOWN     uint8 = 0x0C
+	MAX7219_REG_DISPLAY_TEST uint8 = 0x0F
+
+	// Register values: no BCD decoding, scan all eight digits
+	MAX7219_NO_DECODE uint8 = 0x00
+	MAX7219_SCAN_ALL  uint8 = 0x07
+	MAX7219_SHUTDOWN  uint8 = 0x00
+	MAX7219_NORMAL_OP uint8 = 0x01
+	MAX7219_TEST_OFF  uint8 = 0x00
+)
+
+/*
+ * The matrix is one of the displays the game can draw on
+ */
+var _ display.Display = (*MAX7219)(nil)
+
+/*
+ * The pause between the frames of scrolling text
+ */
+var printPeriod time.Duration = time.Duration(display.SCROLL_PERIOD_MS) * time.Millisecond
+
+/*
+ * The host SPI bus: all the driver needs is to send bytes to the
+ * modules. TinyGo's `*machine.SPI` satisfies this
+ */
+type Bus interface {
+	Tx(w, r []byte) error
+}
+
+/*
+ * The chip select pin, held low while the modules are sent data;
+ * they take it as it goes high. TinyGo's `machine.Pin` satisfies this
+ */
+type Pin interface {
+	High()
+	Low()
+}
+
+/*
+ * One or more daisy-chained MAX7219 8x8 LED matrix modules, which
+ * form a row from the module wired to the host, on the left, to the
+ * last in the chain. (0,0) is the bottom left corner of the first
+ */
+type MAX7219 struct {
+	// Host SPI bus and chip select pin
+	bus Bus
+	cs  Pin
+	// Internal data: module count, brightness level, frame buffer,
+	// one byte for each column across the row of modules
+	modules    uint
+	brightness uint
+	buffer     []byte
+	// Each module's columns as last sent, so that only changes are sent
+	sent   []byte
+	isSent bool
+	// Working space for sending, kept to save garbage collection:
+	// the frame as sent, a value for each module, and the transfer
+	frames   []byte
+	values   []uint8
+	txBuffer []byte
+	// Orientation: applied to each module as the frame buffer is sent
+	rotation    uint
+	isMirroredX bool
+	isMirroredY bool
+}
+
+/*
+ * @brief Convenience method to instantiate a MAX7219 struct.
+ *        The modules can't be read, so there's no way to
+ *        check they're there.
+ *
+ * @param bus:     The host SPI bus, eg. a TinyGo `*machine.SPI`.
+ *                 IMPORTANT This must be configured by the calling
+ *                           application BEFORE calling `Init()` or
+ *                           any other MAX7219 method.
+ * @param cs:      The chip select pin, configured as an output.
+ * @param modules: How many modules are daisy-chained. At least 1.
+ *
+ * @returns The display.
+ */
+func New(bus Bus, cs Pin, modules uint) MAX7219 {
+
+	if modules == 0 {
+		modules = 1
+	}
+
+	return MAX7219{
+		bus:        bus,
+		cs:         cs,
+		modules:    modules,
+		brightness: 15,
+		buffer:     make([]byte, modules*8),
+		sent:       make([]byte, modules*8),
+		frames:     make([]byte, modules*8),
+		values:     make([]uint8, modules),
+		txBuffer:   make([]byte, modules*2),
+	}
+}
+
+/*
+ * @brief Convenience method to set up the modules for an LED matrix,
+ *        power them on, set a default brightness, clear the frame
+ *        buffer and write the buffer to the modules.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) Init() error {
+
+	setup := [][2]uint8{
+		{MAX7219_REG_DISPLAY_TEST, MAX7219_TEST_OFF},
+		{MAX7219_REG_DECODE_MODE, MAX7219_NO_DECODE},
+		{MAX7219_REG_SCAN_LIMIT, MAX7219_SCAN_ALL},
+	}
+
+	for _, command := range setup {
+		if err := p.writeAll(command[0], command[1]); err != nil {
+			return err
+		}
+	}
+
+	if err := p.Power(true); err != nil {
+		return err
+	}
+
+	if err := p.SetBrightness(8); err != nil {
+		return err
+	}
+
+	p.isSent = false
+	p.Clear()
+	return p.Draw()
+}
+
+/*
+ * @brief Turn the modules on or off. The frame is kept while they're off.
+ *
+ * @param isOn: `true` to enable the display, `false` to turn it off.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) Power(isOn bool) error {
+
+	if isOn {
+		return p.writeAll(MAX7219_REG_SHUTDOWN, MAX7219_NORMAL_OP)
+	}
+
+	return p.writeAll(MAX7219_REG_SHUTDOWN, MAX7219_SHUTDOWN)
+}
+
+/*
+ * @brief Set every module's brightness.
+ *        The effect is immediate.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (very bright).
+ *                    Note that 0 does not turn off the display.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) SetBrightness(brightness uint) error {
+
+	if brightness > 15 {
+		brightness = 15
+	}
+
+	p.brightness = brightness
+	return p.writeAll(MAX7219_REG_INTENSITY, uint8(brightness))
+}
+
+/*
+ * @brief Set how the modules are mounted, so that (0,0) stays at the
+ *        bottom left as the viewer sees it, and sprites and text
+ *        appear the right way up. Every module is turned the same
+ *        way. Takes effect at the next `Draw()`.
+ *
+ * @param rotation:    `display.ROTATE_0`, `display.ROTATE_90`,
+ *                     `display.ROTATE_180` or `display.ROTATE_270`.
+ * @param isMirroredX: `true` to flip each module's image left to right.
+ * @param isMirroredY: `true` to flip each module's image top to bottom.
+ */
+func (p *MAX7219) SetOrientation(rotation uint, isMirroredX bool, isMirroredY bool) {
+
+	p.rotation = rotation & 0x03
+	p.isMirroredX = isMirroredX
+	p.isMirroredY = isMirroredY
+}
+
+/*
+ * @brief Get the width of the row of modules.
+ *
+ * @returns The width in pixels.
+ */
+func (p *MAX7219) Width() uint {
+
+	return p.modules * 8
+}
+
+/*
+ * @brief Write a graphic pattern to the first module, clear the
+ *        others, and update the display.
+ *
+ * @param sprite: A graphic stored as an [8]byte array.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) DrawSprite(sprite *graphics.Sprite) error {
+
+	p.Clear()
+	copy(p.buffer, sprite[:])
+	return p.Draw()
+}
+
+/*
+ * @brief Turn a specific pixel on or off. Pixels beyond
+ *        the last module are ignored.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param isSet: `true` to light the pixel, `false` to clear it.
+ */
+func (p *MAX7219) Plot(x uint, y uint, isSet bool) {
+
+	if x >= p.Width() || y > 7 {
+		return
+	}
+
+	if isSet {
+		p.buffer[x] |= 1 << y
+	} else {
+		p.buffer[x] &= ^(1 << y)
+	}
+}
+
+/*
+ * @brief Is a specific pixel in the frame buffer lit?
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `true` if the pixel is set, `false` if it's clear
+ *          or off the display.
+ */
+func (p *MAX7219) Get(x uint, y uint) bool {
+
+	if x >= p.Width() || y > 7 {
+		return false
+	}
+
+	return p.buffer[x]&(1<<y) != 0
+}
+
+/*
+ * @brief Scroll a text string across the row of modules. Text that
+ *        fits is shown in the middle, without scrolling.
+ *
+ * @param text: The string to scroll.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) Print(text string) error {
+
+	// Render the text as a row of columns
+	src_buffer := display.TextColumns(text)
+	length := len(src_buffer)
+	width := int(p.Width())
+	if length <= width {
+		src_buffer = display.CentreColumns(src_buffer, width)
+		length = width
+	}
+
+	// Animate the line by repeatedly sending a row's
+	// width of the output buffer to the modules
+	cursor := 0
+	for {
+		copy(p.buffer, src_buffer[cursor:cursor+width])
+		if err := p.Draw(); err != nil {
+			return err
+		}
+
+		cursor += 1
+		if cursor > length-width {
+			break
+		}
+
+		// Pause between frames
+		time.Sleep(printPeriod)
+	}
+
+	return nil
+}
+
+/*
+ * @brief Clear the internal frame buffer.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (p *MAX7219) Clear() {
+
+	for i := range p.buffer {
+		p.buffer[i] = 0x00
+	}
+}
+
+/*
+ * @brief Write the internal frame buffer to the modules. Each of
+ *        the eight digit registers, which hold a column apiece, is
+ *        sent only if it has changed on at least one module.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) Draw() error {
+
+	// Turn each module's frame to suit its mounting
+	for module := uint(0); module < p.modules; module++ {
+		frame := [8]byte{}
+		copy(frame[:], p.buffer[module*8:module*8+8])
+		frame = display.Orient(&frame, p.rotation, p.isMirroredX, p.isMirroredY)
+		copy(p.frames[module*8:], frame[:])
+	}
+
+	// Send every module its column for each digit register in turn
+	for column := uint(0); column < 8; column++ {
+		isChanged := !p.isSent
+		for module := uint(0); module < p.modules; module++ {
+			p.values[module] = p.frames[module*8+column]
+			isChanged = isChanged || p.values[module] != p.sent[module*8+column]
+		}
+
+		if !isChanged {
+			continue
+		}
+
+		if err := p.write(MAX7219_REG_DIGIT_0 + uint8(column)); err != nil {
+			// Whatever got through, send it all next time
+			p.isSent = false
+			return err
+		}
+	}
+
+	copy(p.sent, p.frames)
+	p.isSent = true
+	return nil
+}
+
+/*
+ * @brief Display a series of 8x8 frames on the first module.
+ *
+ * @param sequence:           A slice containing all the frames in order.
+ * @param frameCount:         The number of 8x8 frames in the sequence.
+ * @param interstitialPeriod: The time in ms between frames.
+ *
+ * @returns An error if the modules could not be reached.
+ */
+func (p *MAX7219) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error {
+
+	count := 0
+	for {
+		frame := graphics.Sprite{}
+		copy(frame[:], sequence[count:count+8])
+		if err := p.DrawSprite(&frame); err != nil {
+			return err
+		}
+
+		time.Sleep(time.Millisecond * time.Duration(interstitialPeriod))
+
+		count += 8
+		if count >= (frameCount * 8) {
+			break
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Set the same register to the same value on every module.
+ *
+ * @param register: The register's address.
+ * @param value:    The value.
+ *
+ * @returns The bus error, if any.
+ */
+func (p *MAX7219) writeAll(register uint8, value uint8) error {
+
+	for i := range p.values {
+		p.values[i] = value
+	}
+
+	return p.write(register)
+}
+
+/*
+ * @brief Set a register on every module, in one transfer, to the
+ *        values in `values`, first module first. Data for the last
+ *        module in the chain goes first, as it's shifted on through
+ *        the modules before it.
+ *
+ * @param register: The register's address.
+ *
+ * @returns The bus error, if any.
+ */
+func (p *MAX7219) write(register uint8) error {
+
+	last := len(p.values) - 1
+	for i, value := range p.values {
+		p.txBuffer[(last-i)*2] = register
+		p.txBuffer[(last-i)*2+1] = value
+	}
+
+	// The modules take the data as chip select goes high
+	p.cs.Low()
+	err := p.bus.Tx(p.txBuffer, nil)
+	p.cs.High()
+	return err
+}
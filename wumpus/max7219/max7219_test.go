@@ -0,0 +1,209 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package max7219
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"wumpus/display"
+)
+
+func init() {
+
+	// Scroll text without pausing between frames
+	printPeriod = 0
+}
+
+/*
+ * @brief Make a row of modules on a FakeBus, set up, with nothing
+ *        recorded yet.
+ *
+ * @param t:       The test.
+ * @param modules: How many modules are daisy-chained.
+ *
+ * @returns The modules and their bus.
+ */
+func newTestMatrix(t *testing.T, modules uint) (*MAX7219, *FakeBus) {
+
+	bus := FakeBus{}
+	matrix := New(&bus, &bus, modules)
+	if err := matrix.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Reset()
+	return &matrix, &bus
+}
+
+/*
+ * @brief Check the transfers recorded on a FakeBus, then forget them.
+ *
+ * @param t:        The test.
+ * @param bus:      The bus.
+ * @param expected: The bytes of each transfer expected, in order.
+ */
+func checkTransactions(t *testing.T, bus *FakeBus, expected ...[]byte) {
+
+	t.Helper()
+	if len(expected) == 0 {
+		expected = nil
+	}
+
+	if !reflect.DeepEqual(bus.Transactions, expected) {
+		t.Errorf("sent % X, expected % X", bus.Transactions, expected)
+	}
+
+	if bus.IsSelected {
+		t.Error("chip select left low")
+	}
+
+	bus.Reset()
+}
+
+func TestInit(t *testing.T) {
+
+	bus := FakeBus{}
+	matrix := New(&bus, &bus, 1)
+	if err := matrix.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Test mode off, no decoding, all digits scanned, powered
+	// on and half brightness, then every digit cleared
+	checkTransactions(t, &bus,
+		[]byte{0x0F, 0x00}, []byte{0x09, 0x00}, []byte{0x0B, 0x07},
+		[]byte{0x0C, 0x01}, []byte{0x0A, 0x08},
+		[]byte{0x01, 0x00}, []byte{0x02, 0x00}, []byte{0x03, 0x00}, []byte{0x04, 0x00},
+		[]byte{0x05, 0x00}, []byte{0x06, 0x00}, []byte{0x07, 0x00}, []byte{0x08, 0x00})
+}
+
+func TestInitReportsBusError(t *testing.T) {
+
+	bus := FakeBus{Err: errors.New("bus fault")}
+	matrix := New(&bus, &bus, 1)
+	if err := matrix.Init(); err != bus.Err {
+		t.Errorf("got error %v, expected %v", err, bus.Err)
+	}
+}
+
+func TestDraw(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t, 1)
+
+	// Each column is a digit register, its bottom pixel bit 0
+	matrix.Plot(2, 0, true)
+	matrix.Plot(2, 7, true)
+	matrix.Plot(5, 3, true)
+	if err := matrix.Draw(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkTransactions(t, bus, []byte{0x03, 0x81}, []byte{0x06, 0x08})
+
+	// Nothing has changed, so nothing is sent
+	_ = matrix.Draw()
+	checkTransactions(t, bus)
+
+	matrix.Plot(2, 7, false)
+	_ = matrix.Draw()
+	checkTransactions(t, bus, []byte{0x03, 0x01})
+}
+
+func TestDrawResendsAfterError(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t, 1)
+
+	bus.Err = errors.New("bus fault")
+	matrix.Plot(0, 0, true)
+	if err := matrix.Draw(); err == nil {
+		t.Fatal("no error from a failed transfer")
+	}
+
+	// What the modules show is unknown, so every digit is sent
+	bus.Err = nil
+	_ = matrix.Draw()
+	checkTransactions(t, bus,
+		[]byte{0x01, 0x01}, []byte{0x02, 0x00}, []byte{0x03, 0x00}, []byte{0x04, 0x00},
+		[]byte{0x05, 0x00}, []byte{0x06, 0x00}, []byte{0x07, 0x00}, []byte{0x08, 0x00})
+}
+
+func TestDaisyChain(t *testing.T) {
+
+	matrix, bus := newTestMatrix(t, 3)
+	if matrix.Width() != 24 {
+		t.Errorf("width %d, expected 24", matrix.Width())
+	}
+
+	// Every module is sent the same setting in one transfer
+	_ = matrix.SetBrightness(4)
+	checkTransactions(t, bus, []byte{0x0A, 0x04, 0x0A, 0x04, 0x0A, 0x04})
+
+	// Light column 1 on the first module and on the last:
+	// the last module's data goes first, as it's shifted
+	// on through the modules before it
+	matrix.Plot(1, 0, true)
+	matrix.Plot(17, 1, true)
+	_ = matrix.Draw()
+	checkTransactions(t, bus, []byte{0x02, 0x02, 0x02, 0x00, 0x02, 0x01})
+
+	// A digit is sent to every module when any one changes
+	matrix.Plot(12, 6, true)
+	_ = matrix.Draw()
+	checkTransactions(t, bus, []byte{0x05, 0x00, 0x05, 0x40, 0x05, 0x00})
+
+	// Pixels beyond the last module are ignored
+	matrix.Plot(24, 0, true)
+	_ = matrix.Draw()
+	checkTransactions(t, bus)
+}
+
+func TestSetOrientation(t *testing.T) {
+
+	// Each module is turned the same way: a pixel at the bottom
+	// left of each image is sent in the last column of both
+	matrix, bus := newTestMatrix(t, 2)
+	matrix.SetOrientation(display.ROTATE_90, false, false)
+	matrix.Plot(0, 0, true)
+	matrix.Plot(8, 0, true)
+	_ = matrix.Draw()
+	checkTransactions(t, bus, []byte{0x08, 0x01, 0x08, 0x01})
+}
+
+func FuzzPrint(f *testing.F) {
+
+	for _, text := range []string{"", "I", "HI", "HUNT THE WUMPUS", "20°", "\x00\x1F", "日本", "\xFF\xFE"} {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		matrix, bus := newTestMatrix(t, 2)
+		if err := matrix.Print(text); err != nil {
+			t.Fatal(err)
+		}
+
+		// Text that fits is shown once, in the middle; longer
+		// text scrolls until its end is shown
+		columns := display.TextColumns(text)
+		expected := columns
+		if len(columns) <= 16 {
+			if len(bus.Transactions) > 8 {
+				t.Errorf("%q scrolled through %d transfers", text, len(bus.Transactions))
+			}
+
+			expected = display.CentreColumns(columns, 16)
+		}
+
+		if !bytes.Equal(matrix.buffer, expected[len(expected)-16:]) {
+			t.Errorf("%q left % X", text, matrix.buffer)
+		}
+	})
+}
@@ -0,0 +1,351 @@
+//go:build tinygo
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"machine"
+	rnd "math/rand"
+	"strings"
+	"time"
+	"wumpus/display"
+	"wumpus/engine"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// GPIO pins
+	PIN_SDA     machine.Pin = machine.GP8
+	PIN_SCL     machine.Pin = machine.GP9
+	PIN_GREEN   machine.Pin = machine.GP20
+	PIN_RED     machine.Pin = machine.GP21
+	PIN_SPEAKER machine.Pin = machine.GP16
+	PIN_BUTTON  machine.Pin = machine.GP19
+
+	// Settings are kept at the start of the flash set aside for data
+	SETTINGS_SIZE int = 64
+
+	// How the matrix is mounted: turned clockwise from the
+	// orientation in the circuit diagram, and whether it's mirrored
+	MATRIX_ROTATION uint = display.ROTATE_0
+	MATRIX_MIRROR_X bool = false
+	MATRIX_MIRROR_Y bool = false
+
+	// Set to STATS_SEGMENT7 or STATS_SEGMENT14 to show the session
+	// stats on a segment backpack at STATS_ADDRESS
+	STATS_DISPLAY uint = STATS_NONE
+
+	// Key pad keys, on the first key-scan line
+	KEY_UP    uint = 0
+	KEY_DOWN  uint = 1
+	KEY_LEFT  uint = 2
+	KEY_RIGHT uint = 3
+	KEY_FIRE  uint = 4
+
+	// Joystick readings the key pad stands in for
+	KEYPAD_AXIS_LOW    uint16 = 0
+	KEYPAD_AXIS_CENTRE uint16 = 32767
+	KEYPAD_AXIS_HIGH   uint16 = 65535
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	PIN_Y machine.ADC = machine.ADC{Pin: machine.GP27}
+	PIN_X machine.ADC = machine.ADC{Pin: machine.GP26}
+)
+
+/*
+ * @brief Set up the game hardware.
+ *
+ * @returns `FAIL_NONE` if the hardware was configured, otherwise
+ *          the failure, eg. `FAIL_DISPLAY` if the matrix is missing.
+ */
+func setup() uint {
+
+	// Configure the I2C bus
+	i2c := machine.I2C0
+	err := i2c.Configure(machine.I2CConfig{SCL: PIN_SCL, SDA: PIN_SDA})
+	if err != nil {
+		return FAIL_I2C
+	}
+
+	// The matrix may be refreshed from another goroutine,
+	// so every display on the bus takes turns with it
+	bus := display.NewSharedBus(i2c)
+
+	// Set up the LED matrix, which the game draws on through `matrix`
+	if failure := setupMatrix(bus); failure != FAIL_NONE {
+		return failure
+	}
+
+	// Set up the stats display, if there is one
+	stats = openStats(bus, STATS_DISPLAY)
+
+	// Set up sense indicator output pins:
+	// Green is the Wumpus nearby indicator
+	PIN_GREEN.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	PIN_GREEN.Low()
+
+	// Red is the Pit nearby indicator
+	PIN_RED.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	PIN_RED.Low()
+
+	// Set up the speaker
+	PIN_SPEAKER.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	PIN_SPEAKER.Low()
+
+	// Set up the Fire button
+	PIN_BUTTON.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
+
+	// Set up the X- and Y-axis joystick input
+	machine.InitADC()
+	err = PIN_X.Configure(machine.ADCConfig{})
+	if err != nil {
+		return FAIL_ADC
+	}
+	err = PIN_Y.Configure(machine.ADCConfig{})
+	if err != nil {
+		return FAIL_ADC
+	}
+
+	// Wait 2s to stabilise
+	sleep(2000)
+	return FAIL_NONE
+}
+
+/*
+ * @brief Read the joystick's analog outputs.
+ *
+ * @returns The raw x-axis and y-axis readings.
+ */
+func readJoystick() (uint16, uint16) {
+
+	// Direction keys override the joystick
+	switch {
+	case isKeyDown(KEY_UP):
+		return KEYPAD_AXIS_CENTRE, KEYPAD_AXIS_HIGH
+	case isKeyDown(KEY_DOWN):
+		return KEYPAD_AXIS_CENTRE, KEYPAD_AXIS_LOW
+	case isKeyDown(KEY_LEFT):
+		return KEYPAD_AXIS_HIGH, KEYPAD_AXIS_CENTRE
+	case isKeyDown(KEY_RIGHT):
+		return KEYPAD_AXIS_LOW, KEYPAD_AXIS_CENTRE
+	}
+
+	return PIN_X.Get(), PIN_Y.Get()
+}
+
+/*
+ * @brief Read the Fire button.
+ *
+ * @returns `true` if the button is down, otherwise `false`.
+ */
+func isButtonPressed() bool {
+
+	if isKeyDown(KEY_FIRE) {
+		return true
+	}
+
+	return PIN_BUTTON.Get()
+}
+
+/*
+ * @brief Set the sense indicator LEDs.
+ *
+ * @param isGreenOn: `true` to light the Wumpus nearby LED.
+ * @param isRedOn:   `true` to light the Pit nearby LED.
+ */
+func setLeds(isGreenOn bool, isRedOn bool) {
+
+	PIN_GREEN.Set(isGreenOn)
+	PIN_RED.Set(isRedOn)
+}
+
+/*
+ * @brief Calculate a random number.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: The value.
+ */
+func randomInt(start uint, max uint) uint {
+
+	if max <= start {
+		return start
+	}
+
+	value, err := machine.GetRNG()
+	if err != nil {
+		return start + uint(rnd.Uint32()%uint32(max-start))
+	}
+
+	return start + uint(value)%(max-start)
+}
+
+/*
+ * @brief Get a fresh seed for a new board.
+ *
+ * @returns The seed.
+ */
+func randomSeed() uint32 {
+
+	value, err := machine.GetRNG()
+	if err != nil {
+		return rnd.Uint32()
+	}
+
+	return value
+}
+
+/*
+ * @brief Send a game recording to the host over USB serial.
+ *
+ * @param text: The recording.
+ */
+func saveRecording(text string) {
+
+	_, _ = machine.Serial.Write([]byte(text))
+}
+
+/*
+ * @brief Read the settings saved in flash.
+ *
+ * @returns The settings, and `true` if there were any.
+ */
+func loadSettings() (string, bool) {
+
+	data := make([]byte, SETTINGS_SIZE)
+	if _, err := machine.Flash.ReadAt(data, 0); err != nil {
+		return "", false
+	}
+
+	// Erased flash reads as 0xFF, so look for the end of the line
+	text, _, ok := strings.Cut(string(data), "\n")
+	return text, ok
+}
+
+/*
+ * @brief Save the settings in flash, so they survive power cycles.
+ *
+ * @param text: The settings.
+ */
+func saveSettings(text string) {
+
+	if len(text) >= SETTINGS_SIZE {
+		return
+	}
+
+	if err := machine.Flash.EraseBlocks(0, 1); err != nil {
+		return
+	}
+
+	_, _ = machine.Flash.WriteAt([]byte(text+"\n"), 0)
+}
+
+/*
+ * @brief Read a game recording sent by the host over USB serial.
+ *        Data must already be waiting; reading stops at the
+ *        recording's footer line, or after a second's silence.
+ *
+ * @returns The recording, and `true` if one was received.
+ */
+func loadRecording() (string, bool) {
+
+	if machine.Serial.Buffered() == 0 {
+		return "", false
+	}
+
+	var text strings.Builder
+	lastRead := time.Now()
+	for time.Since(lastRead) < time.Second {
+		if machine.Serial.Buffered() == 0 {
+			sleep(1)
+			continue
+		}
+
+		value, err := machine.Serial.ReadByte()
+		if err != nil {
+			return "", false
+		}
+
+		text.WriteByte(value)
+		lastRead = time.Now()
+		if value == '\n' && strings.HasSuffix(strings.TrimRight(text.String(), "\r\n"), engine.RECORDING_FOOTER) {
+			return text.String(), true
+		}
+	}
+
+	return "", false
+}
+
+/*
+ * @brief Play a sound on the piezo buzzer.
+ *
+ * @param frequency: The sound's frequency in Hz.
+ * @param duration:  How long the sound plays in ms.
+ * @param post:      A delay added after the sound has played.
+ *
+ * @returns: The value.
+ */
+func tone(frequency uint, duration int, post uint32) {
+
+	// Get the cycle period in microseconds
+	var period float32 = 1000000.0 / float32(frequency)
+	period /= 2
+
+	// Get the microsecond timer now
+	start := time.Now()
+
+	// Loop until duration (ms) in microseconds has elapsed
+	for time.Since(start).Microseconds() < int64(duration*1000) {
+		PIN_SPEAKER.High()
+		time.Sleep(time.Duration(period) * time.Microsecond)
+		PIN_SPEAKER.Low()
+		time.Sleep(time.Duration(period) * time.Microsecond)
+	}
+
+	// Apply a post-tone delay
+	if post != 0 {
+		sleep(post)
+	}
+}
+
+/*
+ * @brief Flash the Pico led continuously to signal
+ *        hardware failure: one flash for an I2C bus
+ *        failure, two for a missing display, three for
+ *        a joystick (ADC) failure, four for a terminal
+ *        failure (simulator only, so never seen here),
+ *        five for an SPI bus failure, then a pause.
+ *
+ * @param failure: The failure, eg. `FAIL_DISPLAY`.
+ */
+func failLoop(failure uint) {
+
+	led := machine.LED
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	for {
+		var i uint
+		for i = 0; i < failure; i++ {
+			led.High()
+			sleep(200)
+			led.Low()
+			sleep(200)
+		}
+
+		sleep(1000)
+	}
+}
@@ -0,0 +1,109 @@
+//go:build tinygo && !max7219 && !ssd1306 && !ws2812
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"time"
+	"wumpus/display"
+	"wumpus/ht16k33"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Set to true for a red/green bicolor matrix
+	MATRIX_IS_BICOLOR bool = false
+
+	// How many intensities a single-colour matrix shows: 2 for
+	// just on and off, or 3 or 4 to dim the squares you've visited
+	MATRIX_GREY_LEVELS uint = 2
+
+	// Set to true to read a key pad wired to the matrix backpack's
+	// key-scan lines, alongside the joystick and Fire button
+	USE_KEYPAD bool = false
+
+	// How often to read the key pad
+	KEYPAD_PERIOD_MS int64 = 10
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// The matrix backpack, which also scans the key pad
+	backpack ht16k33.HT16K33
+
+	// When the key pad was last read
+	lastKeypadRead time.Time
+)
+
+/*
+ * @brief Set up the HT16K33 LED matrix backpack.
+ *
+ * @param i2c: The configured I2C bus the backpack is on.
+ *
+ * @returns `FAIL_NONE` if the matrix was set up, otherwise `FAIL_DISPLAY`.
+ */
+func setupMatrix(i2c *display.SharedBus) uint {
+
+	var err error
+	backpack, err = ht16k33.New(i2c, ht16k33.HT16K33_ADDRESS)
+	if err != nil {
+		return FAIL_DISPLAY
+	}
+
+	backpack.SetOrientation(MATRIX_ROTATION, MATRIX_MIRROR_X, MATRIX_MIRROR_Y)
+	backpack.SetBicolor(MATRIX_IS_BICOLOR)
+	backpack.SetGreyscale(MATRIX_GREY_LEVELS)
+	if backpack.Init() != nil || backpack.SetBrightness(4) != nil {
+		return FAIL_DISPLAY
+	}
+
+	// Free the ROW/INT pin for key scanning
+	if USE_KEYPAD && backpack.SetRowInt(ht16k33.HT16K33_ROW_INT_LOW) != nil {
+		return FAIL_DISPLAY
+	}
+
+	matrix = &backpack
+	return FAIL_NONE
+}
+
+/*
+ * @brief Check a key pad key, if the key pad is in use.
+ *
+ * @param key: The key, eg. `KEY_FIRE`.
+ *
+ * @returns `true` if the key is down, otherwise `false`.
+ */
+func isKeyDown(key uint) bool {
+
+	if !USE_KEYPAD {
+		return false
+	}
+
+	readKeypad()
+	return backpack.IsKeyDown(key)
+}
+
+/*
+ * @brief Update the key pad state, if it's due to be read.
+ */
+func readKeypad() {
+
+	if time.Since(lastKeypadRead).Milliseconds() < KEYPAD_PERIOD_MS {
+		return
+	}
+
+	lastKeypadRead = time.Now()
+	_, _ = backpack.KeyEvents()
+}
@@ -0,0 +1,89 @@
+//go:build tinygo && max7219
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"machine"
+	"wumpus/display"
+	"wumpus/max7219"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// SPI0 pins for the MAX7219 modules
+	PIN_MATRIX_SCK machine.Pin = machine.GP2
+	PIN_MATRIX_SDO machine.Pin = machine.GP3
+	PIN_MATRIX_CS  machine.Pin = machine.GP5
+
+	// How many modules are daisy-chained. The game
+	// plays on the first, nearest the Pico
+	MATRIX_MODULES uint = 1
+
+	// The modules take up to 10MHz; this is plenty
+	MATRIX_SPI_FREQUENCY uint32 = 1000000
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// The matrix modules
+	modules max7219.MAX7219
+)
+
+/*
+ * @brief Set up the MAX7219 LED matrix modules. They can't
+ *        be read, so a missing module can't be detected.
+ *
+ * @param i2c: Unused -- the modules are on the SPI bus.
+ *
+ * @returns `FAIL_NONE` if the matrix was set up, otherwise
+ *          `FAIL_SPI` or `FAIL_DISPLAY`.
+ */
+func setupMatrix(i2c *display.SharedBus) uint {
+
+	spi := machine.SPI0
+	err := spi.Configure(machine.SPIConfig{
+		Frequency: MATRIX_SPI_FREQUENCY,
+		SCK:       PIN_MATRIX_SCK,
+		SDO:       PIN_MATRIX_SDO,
+	})
+	if err != nil {
+		return FAIL_SPI
+	}
+
+	PIN_MATRIX_CS.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	PIN_MATRIX_CS.High()
+
+	modules = max7219.New(spi, PIN_MATRIX_CS, MATRIX_MODULES)
+	modules.SetOrientation(MATRIX_ROTATION, MATRIX_MIRROR_X, MATRIX_MIRROR_Y)
+	if modules.Init() != nil || modules.SetBrightness(4) != nil {
+		return FAIL_DISPLAY
+	}
+
+	matrix = &modules
+	return FAIL_NONE
+}
+
+/*
+ * @brief Check a key pad key. The MAX7219 can't scan keys.
+ *
+ * @param key: The key, eg. `KEY_FIRE`.
+ *
+ * @returns Always `false`.
+ */
+func isKeyDown(key uint) bool {
+
+	return false
+}
@@ -0,0 +1,67 @@
+//go:build tinygo && ssd1306
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"wumpus/display"
+	"wumpus/ssd1306"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// The OLED's I2C address: 0x3C, or 0x3D on some boards
+	OLED_ADDRESS uint8 = ssd1306.SSD1306_ADDRESS
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// The OLED, and the map and status panel drawn on it
+	oled       ssd1306.SSD1306
+	oledScreen ssd1306.Screen
+)
+
+/*
+ * @brief Set up the SSD1306 OLED, which shows the matrix
+ *        scaled up, with the game's status beside it.
+ *
+ * @param i2c: The configured I2C bus the OLED is on.
+ *
+ * @returns `FAIL_NONE` if the OLED was set up, otherwise `FAIL_DISPLAY`.
+ */
+func setupMatrix(i2c *display.SharedBus) uint {
+
+	var err error
+	oled, err = ssd1306.New(i2c, OLED_ADDRESS)
+	if err != nil || oled.Init() != nil {
+		return FAIL_DISPLAY
+	}
+
+	oledScreen = ssd1306.NewScreen(&oled)
+	matrix = &oledScreen
+	return FAIL_NONE
+}
+
+/*
+ * @brief Check a key pad key. The OLED has no key scan.
+ *
+ * @param key: The key, eg. `KEY_FIRE`.
+ *
+ * @returns Always `false`.
+ */
+func isKeyDown(key uint) bool {
+
+	return false
+}
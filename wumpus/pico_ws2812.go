@@ -0,0 +1,86 @@
+//go:build tinygo && ws2812
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"machine"
+	"wumpus/display"
+	"wumpus/ws2812"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// SPI0 pins: the LEDs' data line is driven from SDO.
+	// SCK is set up but not connected
+	PIN_PANEL_SCK machine.Pin = machine.GP2
+	PIN_PANEL_SDO machine.Pin = machine.GP3
+
+	// How the panel's LEDs are chained, from the first, at the bottom left:
+	// WS2812_LAYOUT_PROGRESSIVE if every row runs left to right, or
+	// WS2812_LAYOUT_SERPENTINE if the rows alternate direction
+	PANEL_LAYOUT uint = ws2812.WS2812_LAYOUT_PROGRESSIVE
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// The LED panel, and the SPI output that drives it
+	panelOutput ws2812.SPIOutput
+	panel       ws2812.WS2812
+)
+
+/*
+ * @brief Set up the WS2812 RGB LED panel.
+ *
+ * @param i2c: Unused -- the panel is driven from the SPI bus.
+ *
+ * @returns `FAIL_NONE` if the panel was set up, otherwise
+ *          `FAIL_SPI` or `FAIL_DISPLAY`.
+ */
+func setupMatrix(i2c *display.SharedBus) uint {
+
+	spi := machine.SPI0
+	err := spi.Configure(machine.SPIConfig{
+		Frequency: ws2812.WS2812_SPI_FREQUENCY,
+		SCK:       PIN_PANEL_SCK,
+		SDO:       PIN_PANEL_SDO,
+	})
+	if err != nil {
+		return FAIL_SPI
+	}
+
+	panelOutput = ws2812.NewSPIOutput(spi)
+	panel = ws2812.New(&panelOutput)
+	panel.SetLayout(PANEL_LAYOUT)
+	panel.SetOrientation(MATRIX_ROTATION, MATRIX_MIRROR_X, MATRIX_MIRROR_Y)
+	if panel.Init() != nil || panel.SetBrightness(4) != nil {
+		return FAIL_DISPLAY
+	}
+
+	matrix = &panel
+	return FAIL_NONE
+}
+
+/*
+ * @brief Check a key pad key. The panel has no key scan.
+ *
+ * @param key: The key, eg. `KEY_FIRE`.
+ *
+ * @returns Always `false`.
+ */
+func isKeyDown(key uint) bool {
+
+	return false
+}
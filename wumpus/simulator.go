@@ -0,0 +1,453 @@
+//go:build !tinygo
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	rnd "math/rand"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	"wumpus/engine"
+	"wumpus/ht16k33"
+	"wumpus/ssd1306"
+	"wumpus/ws2812"
+)
+
+/*
+ * CONSTANTS
+ */
+const (
+	// Simulated joystick readings: centre and the two extremes
+	SIM_JOYSTICK_CENTRE uint16 = 32767
+	SIM_JOYSTICK_LOW    uint16 = 2048
+	SIM_JOYSTICK_HIGH   uint16 = 65000
+
+	// How long a tap of the space bar holds down the Fire button.
+	// Long enough for the debounce check to see it twice
+	SIM_BUTTON_HOLD_MS int64 = 150
+)
+
+/*
+ * GLOBALS
+ */
+var (
+	// The terminal standing in for the display
+	screen terminalBus
+
+	// Simulated sense indicator LEDs
+	isGreenLedOn bool
+	isRedLedOn   bool
+
+	// Simulated joystick and button state
+	keyDirections     chan uint = make(chan uint, 8)
+	isJoystickPushed  bool
+	buttonReleaseTime time.Time
+	buttonEvents      chan bool = make(chan bool, 8)
+
+	// The terminal settings to restore on exit
+	savedTerminalState string
+
+	// Where to save game recordings, and one to play back
+	recordPath    string
+	replayText    string
+	isReplayReady bool
+
+	// Where to keep the player's settings between sessions
+	settingsPath string
+)
+
+/*
+ * @brief Set up the terminal in place of the game hardware.
+ *
+ * @returns `FAIL_NONE` if the terminal was configured, otherwise
+ *          the failure.
+ */
+func setup() uint {
+
+	// Check for a game code to replay in the first round
+	flag.Func("code", "replay the cave with this game code, eg. \"GN 1A2B3C4D\"", func(value string) error {
+		if !requestGame(value) {
+			return errors.New("bad game code")
+		}
+
+		return nil
+	})
+	flag.StringVar(&recordPath, "record", "", "save each game's recording to this file")
+	replayPath := flag.String("replay", "", "play back the game recorded in this file")
+	flag.BoolVar(&screen.isBicolor, "bicolor", false, "show a red/green bicolor matrix")
+	flag.BoolVar(&screen.isOled, "oled", false, "show a 128x64 OLED with a status panel in place of the matrix")
+	flag.BoolVar(&screen.isRgb, "rgb", false, "show an RGB LED panel in place of the matrix")
+	flag.Func("stats", "show the session stats on a 7- or 14-segment display", func(value string) error {
+		switch value {
+		case "7":
+			screen.statsKind = STATS_SEGMENT7
+		case "14":
+			screen.statsKind = STATS_SEGMENT14
+		default:
+			return errors.New("must be 7 or 14")
+		}
+
+		return nil
+	})
+	flag.StringVar(&settingsPath, "settings", defaultSettingsPath(), "keep the chosen difficulty level in this file")
+	flag.Parse()
+
+	if *replayPath != "" {
+		data, err := os.ReadFile(*replayPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		replayText = string(data)
+		isReplayReady = true
+	}
+
+	// Put the terminal into unbuffered, no-echo mode
+	// so that single key presses can be read
+	state, err := stty("-g")
+	if err != nil {
+		return FAIL_TERMINAL
+	}
+
+	savedTerminalState = strings.TrimSpace(state)
+	if _, err = stty("cbreak", "-echo"); err != nil {
+		return FAIL_TERMINAL
+	}
+
+	// Make sure Ctrl-C leaves the terminal usable
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		quit(0)
+	}()
+
+	// Clear the screen and hide the cursor
+	fmt.Print("\x1b[2J\x1b[?25l")
+
+	// Set up the LED matrix, the OLED or the RGB panel, drawn in the terminal
+	if screen.isRgb {
+		panel := ws2812.New(&screen)
+		if panel.Init() != nil || panel.SetBrightness(15) != nil {
+			return FAIL_DISPLAY
+		}
+
+		matrix = &panel
+	} else if screen.isOled {
+		oled, err := ssd1306.New(&screen, ssd1306.SSD1306_ADDRESS)
+		if err != nil || oled.Init() != nil {
+			return FAIL_DISPLAY
+		}
+
+		oledScreen := ssd1306.NewScreen(&oled)
+		matrix = &oledScreen
+	} else {
+		backpack, err := ht16k33.New(&screen, ht16k33.HT16K33_ADDRESS)
+		backpack.SetBicolor(screen.isBicolor)
+		if err != nil || backpack.Init() != nil || backpack.SetBrightness(4) != nil {
+			return FAIL_DISPLAY
+		}
+
+		matrix = &backpack
+	}
+
+	// Set up the stats display, if one was asked for
+	stats = openStats(&screen, screen.statsKind)
+
+	// Keep the display blinking between writes
+	go screen.blink()
+
+	// Start reading the keyboard
+	go readKeys()
+	return FAIL_NONE
+}
+
+/*
+ * @brief Read the keyboard continuously: arrow keys stand in for the
+ *        joystick, space for the Fire button and Q quits.
+ */
+func readKeys() {
+
+	input := make([]byte, 8)
+	for {
+		count, err := os.Stdin.Read(input)
+		if err != nil {
+			quit(1)
+		}
+
+		for i := 0; i < count; i++ {
+			switch input[i] {
+			case ' ':
+				buttonEvents <- true
+			case 'q', 'Q':
+				quit(0)
+			case 0x1B:
+				// Arrow keys arrive as ESC [ A-D
+				if i+2 < count && input[i+1] == '[' {
+					switch input[i+2] {
+					case 'A':
+						keyDirections <- engine.UP
+					case 'B':
+						keyDirections <- engine.DOWN
+					case 'C':
+						keyDirections <- engine.RIGHT
+					case 'D':
+						keyDirections <- engine.LEFT
+					}
+					i += 2
+				}
+			}
+		}
+	}
+}
+
+/*
+ * @brief Read the simulated joystick. Each arrow key press
+ *        pushes the stick for one reading, then re-centres it.
+ *
+ * @returns The raw x-axis and y-axis readings.
+ */
+func readJoystick() (uint16, uint16) {
+
+	if isJoystickPushed {
+		isJoystickPushed = false
+		return SIM_JOYSTICK_CENTRE, SIM_JOYSTICK_CENTRE
+	}
+
+	select {
+	case key := <-keyDirections:
+		isJoystickPushed = true
+		switch key {
+		case engine.UP:
+			return SIM_JOYSTICK_CENTRE, SIM_JOYSTICK_HIGH
+		case engine.DOWN:
+			return SIM_JOYSTICK_CENTRE, SIM_JOYSTICK_LOW
+		case engine.LEFT:
+			return SIM_JOYSTICK_HIGH, SIM_JOYSTICK_CENTRE
+		case engine.RIGHT:
+			return SIM_JOYSTICK_LOW, SIM_JOYSTICK_CENTRE
+		}
+	default:
+	}
+
+	return SIM_JOYSTICK_CENTRE, SIM_JOYSTICK_CENTRE
+}
+
+/*
+ * @brief Read the simulated Fire button.
+ *
+ * @returns `true` if the button is down, otherwise `false`.
+ */
+func isButtonPressed() bool {
+
+	select {
+	case <-buttonEvents:
+		buttonReleaseTime = time.Now().Add(time.Duration(SIM_BUTTON_HOLD_MS) * time.Millisecond)
+	default:
+	}
+
+	return time.Now().Before(buttonReleaseTime)
+}
+
+/*
+ * @brief Set the sense indicator LEDs.
+ *
+ * @param isGreenOn: `true` to light the Wumpus nearby LED.
+ * @param isRedOn:   `true` to light the Pit nearby LED.
+ */
+func setLeds(isGreenOn bool, isRedOn bool) {
+
+	// The LED states are read by render(), which the blink goroutine calls
+	screen.lock.Lock()
+	defer screen.lock.Unlock()
+
+	if isGreenOn != isGreenLedOn || isRedOn != isRedLedOn {
+		isGreenLedOn = isGreenOn
+		isRedLedOn = isRedOn
+		screen.render()
+	}
+}
+
+/*
+ * @brief Calculate a random number.
+ *
+ * @param start: The baseline value.
+ * @param max:   One above the highest possible roll.
+ *
+ * @returns: The value.
+ */
+func randomInt(start uint, max uint) uint {
+
+	if max <= start {
+		return start
+	}
+
+	return start + uint(rnd.Uint32()%uint32(max-start))
+}
+
+/*
+ * @brief Get a fresh seed for a new board.
+ *
+ * @returns The seed.
+ */
+func randomSeed() uint32 {
+
+	return rnd.Uint32()
+}
+
+/*
+ * @brief Save a game recording to the file named with `-record`.
+ *
+ * @param text: The recording.
+ */
+func saveRecording(text string) {
+
+	if recordPath != "" {
+		_ = os.WriteFile(recordPath, []byte(text), 0644)
+	}
+}
+
+/*
+ * @brief Get the recording named with `-replay`, once.
+ *
+ * @returns The recording, and `true` if there is one to play.
+ */
+func loadRecording() (string, bool) {
+
+	if !isReplayReady {
+		return "", false
+	}
+
+	isReplayReady = false
+	return replayText, true
+}
+
+/*
+ * @brief Read the settings saved by the last session.
+ *
+ * @returns The settings, and `true` if there were any.
+ */
+func loadSettings() (string, bool) {
+
+	if settingsPath == "" {
+		return "", false
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
+
+/*
+ * @brief Save the settings for the next session.
+ *
+ * @param text: The settings.
+ */
+func saveSettings(text string) {
+
+	if settingsPath == "" {
+		return
+	}
+
+	_ = os.MkdirAll(filepath.Dir(settingsPath), 0755)
+	_ = os.WriteFile(settingsPath, []byte(text+"\n"), 0644)
+}
+
+/*
+ * @brief Get the usual place to keep the settings: a file
+ *        in the user's configuration directory.
+ *
+ * @returns The file's path, or an empty string if there is
+ *          no configuration directory.
+ */
+func defaultSettingsPath() string {
+
+	directory, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(directory, "wumpus", "settings")
+}
+
+/*
+ * @brief Stand-in for the piezo buzzer: stay silent,
+ *        but take as long as the sound would.
+ *
+ * @param frequency: The sound's frequency in Hz.
+ * @param duration:  How long the sound plays in ms.
+ * @param post:      A delay added after the sound has played.
+ */
+func tone(frequency uint, duration int, post uint32) {
+
+	sleep(uint32(duration) + post)
+}
+
+/*
+ * @brief Report hardware failure and exit.
+ *
+ * @param failure: The failure, eg. `FAIL_TERMINAL`.
+ */
+func failLoop(failure uint) {
+
+	switch failure {
+	case FAIL_TERMINAL:
+		fmt.Fprintln(os.Stderr, "Could not set up the terminal")
+	case FAIL_DISPLAY:
+		fmt.Fprintln(os.Stderr, "Could not set up the display")
+	default:
+		fmt.Fprintln(os.Stderr, "Could not set up the hardware")
+	}
+
+	quit(1)
+}
+
+/*
+ * @brief Restore the terminal and exit.
+ *
+ * @param code: The process exit code.
+ */
+func quit(code int) {
+
+	fmt.Print("\x1b[?25h\n")
+	if savedTerminalState != "" {
+		_, _ = stty(savedTerminalState)
+	}
+
+	os.Exit(code)
+}
+
+/*
+ * @brief Run `stty` on the controlling terminal.
+ *
+ * @param args: The `stty` arguments.
+ *
+ * @returns The command's output, and an error if it failed.
+ */
+func stty(args ...string) (string, error) {
+
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
@@ -0,0 +1,455 @@
+//go:build !tinygo
+
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"wumpus/graphics"
+	"wumpus/ht16k33"
+	"wumpus/ssd1306"
+	"wumpus/ws2812"
+)
+
+/*
+ * A stand-in for the I2C bus that decodes what the HT16K33 and
+ * SSD1306 drivers send, and for the WS2812 driver's output, and
+ * draws the displays in the terminal, with the sense LEDs below
+ */
+type terminalBus struct {
+	// Writes and blink redraws come from different goroutines
+	lock sync.Mutex
+	// Display state: whether the matrix is bicolor, power,
+	// blink rate and the chip's display RAM
+	isBicolor bool
+	isOn      bool
+	blinkRate byte
+	ram       [16]byte
+	// The stats display: which kind, if any, and its display RAM
+	statsKind uint
+	statsRam  [16]byte
+	// The OLED, if it stands in for the matrix: power, display RAM,
+	// the window that RAM writes fill -- first and last column, then
+	// first and last page -- the next byte's place, and whether the
+	// RAM has changed since it was last drawn
+	isOled        bool
+	isOledOn      bool
+	oledRam       [1024]byte
+	oledWindow    [4]int
+	oledColumn    int
+	oledPage      int
+	isOledChanged bool
+	// The RGB panel, if it stands in for the matrix,
+	// and its colour data as last sent
+	isRgb   bool
+	rgbData [ws2812.WS2812_PIXELS * 3]byte
+}
+
+/*
+ * @brief Receive a write from a display driver and update the terminal.
+ *
+ * @param addr: The target device's I2C address.
+ * @param w:    The bytes to write.
+ * @param r:    Ignored -- nothing is read back.
+ *
+ * @returns An error if there is no stats display or OLED at
+ *          its address, otherwise `nil`.
+ */
+func (p *terminalBus) Tx(addr uint16, w, r []byte) error {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if addr == uint16(ssd1306.SSD1306_ADDRESS) {
+		// The OLED is sent a page at a time, so
+		// leave it to `blink()` to draw
+		if !p.isOled {
+			return errors.New("no display")
+		}
+
+		p.oledWrite(w)
+		return nil
+	}
+
+	if addr == uint16(STATS_ADDRESS) {
+		// The stats display ignores setup: it's always on
+		if p.statsKind == STATS_NONE {
+			return errors.New("no display")
+		}
+
+		if len(w) > 1 {
+			copy(p.statsRam[int(w[0])&0x0F:], w[1:])
+			p.render()
+		}
+
+		return nil
+	}
+
+	if len(w) == 1 {
+		// Single-byte commands: only display setup matters here
+		if w[0]&0xF0 == ht16k33.HT16K33_CMD_BLINK {
+			p.isOn = w[0]&0x01 != 0
+			p.blinkRate = (w[0] >> 1) & 0x03
+		}
+	} else if len(w) > 1 {
+		// Display RAM write: the first byte is the start address
+		copy(p.ram[int(w[0])&0x0F:], w[1:])
+	}
+
+	p.render()
+	return nil
+}
+
+/*
+ * @brief Receive colour data from the WS2812 driver
+ *        and update the terminal.
+ *
+ * @param data: Three bytes for each LED, green, red then blue.
+ *
+ * @returns `nil`.
+ */
+func (p *terminalBus) WritePixels(data []byte) error {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	copy(p.rgbData[:], data)
+	p.render()
+	return nil
+}
+
+/*
+ * @brief Redraw the terminal every so often while the display is
+ *        blinking, as the real display blinks by itself, or once
+ *        the OLED has been written to.
+ */
+func (p *terminalBus) blink() {
+
+	for {
+		time.Sleep(100 * time.Millisecond)
+		p.lock.Lock()
+		if p.blinkRate > 0 || p.isOledChanged {
+			p.isOledChanged = false
+			p.render()
+		}
+
+		p.lock.Unlock()
+	}
+}
+
+/*
+ * @brief Decode a write to the OLED: commands, of which only power
+ *        and the RAM window matter here, or display RAM data.
+ *
+ * @param w: The bytes written, after a control byte.
+ */
+func (p *terminalBus) oledWrite(w []byte) {
+
+	if len(w) < 2 {
+		return
+	}
+
+	if w[0] == ssd1306.SSD1306_CONTROL_DATA {
+		// Fill the window column by column, then page by page
+		for _, value := range w[1:] {
+			p.oledRam[(p.oledPage&0x07)*128+(p.oledColumn&0x7F)] = value
+			p.oledColumn += 1
+			if p.oledColumn > p.oledWindow[1] {
+				p.oledColumn = p.oledWindow[0]
+				p.oledPage += 1
+				if p.oledPage > p.oledWindow[3] {
+					p.oledPage = p.oledWindow[2]
+				}
+			}
+		}
+
+		p.isOledChanged = true
+		return
+	}
+
+	commands := w[1:]
+	for i := 0; i < len(commands); i++ {
+		switch commands[i] {
+		case ssd1306.SSD1306_CMD_DISPLAY_ON, ssd1306.SSD1306_CMD_DISPLAY_OFF:
+			p.isOledOn = commands[i] == ssd1306.SSD1306_CMD_DISPLAY_ON
+			p.isOledChanged = true
+		case ssd1306.SSD1306_CMD_COLUMN_ADDRESS, ssd1306.SSD1306_CMD_PAGE_ADDRESS:
+			// Set the window and move to its start
+			if i+2 < len(commands) {
+				window := 0
+				if commands[i] == ssd1306.SSD1306_CMD_PAGE_ADDRESS {
+					window = 2
+				}
+
+				p.oledWindow[window] = int(commands[i+1])
+				p.oledWindow[window+1] = int(commands[i+2])
+				p.oledColumn = p.oledWindow[0]
+				p.oledPage = p.oledWindow[2]
+			}
+
+			i += 2
+		case ssd1306.SSD1306_CMD_CLOCK_DIVIDE, ssd1306.SSD1306_CMD_MULTIPLEX,
+			ssd1306.SSD1306_CMD_DISPLAY_OFFSET, ssd1306.SSD1306_CMD_CHARGE_PUMP,
+			ssd1306.SSD1306_CMD_MEMORY_MODE, ssd1306.SSD1306_CMD_COM_PINS,
+			ssd1306.SSD1306_CMD_CONTRAST, ssd1306.SSD1306_CMD_PRECHARGE,
+			ssd1306.SSD1306_CMD_VCOM_DETECT:
+			// Skip the command's argument
+			i += 1
+		}
+	}
+}
+
+/*
+ * @brief Draw the OLED in the terminal, two rows
+ *        of pixels to each line of characters.
+ *
+ * @param output: Where to draw.
+ */
+func (p *terminalBus) renderOled(output *strings.Builder) {
+
+	output.WriteString("  +" + strings.Repeat("-", 128) + "+\n")
+	for y := 0; y < 64; y += 2 {
+		output.WriteString("  |")
+		for x := 0; x < 128; x++ {
+			column := p.oledRam[(y/8)*128+x]
+			isTop := p.isOledOn && column&(1<<uint(y%8)) != 0
+			isBottom := p.isOledOn && column&(2<<uint(y%8)) != 0
+			switch {
+			case isTop && isBottom:
+				output.WriteString("█")
+			case isTop:
+				output.WriteString("▀")
+			case isBottom:
+				output.WriteString("▄")
+			default:
+				output.WriteString(" ")
+			}
+		}
+
+		output.WriteString("|\n")
+	}
+
+	output.WriteString("  +" + strings.Repeat("-", 128) + "+\n\n")
+}
+
+/*
+ * @brief Draw the matrix, the OLED or the RGB panel, and the sense
+ *        LEDs in the terminal. Row 7 is at the top, as on the real display.
+ */
+func (p *terminalBus) render() {
+
+	// Blinking hides the display for half of each period,
+	// which is 0.5s at 2Hz, doubling for each slower rate
+	isVisible := p.isOn
+	if p.blinkRate > 0 {
+		period := int64(250) << p.blinkRate
+		isVisible = isVisible && time.Now().UnixMilli()%period < period/2
+	}
+
+	var output strings.Builder
+	output.WriteString("\x1b[H\n  HUNT THE WUMPUS\n\n")
+	if p.isOled {
+		p.renderOled(&output)
+	} else if p.isRgb {
+		p.renderRgb(&output)
+	} else {
+		p.renderMatrix(&output, isVisible)
+	}
+
+	output.WriteString("  " + led(isGreenLedOn, 32) + " Wumpus   " + led(isRedLedOn, 31) + " Pit\n\n")
+	if p.statsKind != STATS_NONE {
+		output.WriteString("  \x1b[91m" + p.statsText() + "\x1b[0m\n\n")
+	}
+
+	output.WriteString("  Arrow keys: move   Space: fire   Q: quit\n")
+	fmt.Print(output.String())
+}
+
+/*
+ * @brief Draw the RGB panel in the terminal, in 24-bit colour.
+ *        The LEDs are chained row by row from the bottom left.
+ *
+ * @param output: Where to draw.
+ */
+func (p *terminalBus) renderRgb(output *strings.Builder) {
+
+	output.WriteString("  +----------------+\n")
+	for y := 7; y >= 0; y-- {
+		output.WriteString("  |")
+		for x := 0; x < 8; x++ {
+			led := p.rgbData[(y*8+x)*3:]
+			if led[0] == 0 && led[1] == 0 && led[2] == 0 {
+				output.WriteString("\x1b[90m··\x1b[0m")
+			} else {
+				output.WriteString(fmt.Sprintf("\x1b[38;2;%d;%d;%dm██\x1b[0m", led[1], led[0], led[2]))
+			}
+		}
+		output.WriteString("|\n")
+	}
+
+	output.WriteString("  +----------------+\n\n")
+}
+
+/*
+ * @brief Draw the matrix in the terminal.
+ *
+ * @param output:    Where to draw.
+ * @param isVisible: `false` if the matrix is blinked off.
+ */
+func (p *terminalBus) renderMatrix(output *strings.Builder, isVisible bool) {
+
+	output.WriteString("  +----------------+\n")
+	for y := 7; y >= 0; y-- {
+		output.WriteString("  |")
+		for x := 0; x < 8; x++ {
+			// A bicolor matrix has green and red bytes for each column;
+			// a single-colour one has the driver's one-bit rotation to undo
+			green := p.ram[x*2]
+			red := byte(0)
+			if p.isBicolor {
+				red = p.ram[x*2+1]
+			} else {
+				green = (green << 1) | (green >> 7)
+			}
+
+			isGreen := green&(1<<y) != 0
+			isRed := red&(1<<y) != 0
+			switch {
+			case !isVisible || (!isGreen && !isRed):
+				output.WriteString("\x1b[90m··\x1b[0m")
+			case !p.isBicolor || (isGreen && isRed):
+				output.WriteString("\x1b[93m██\x1b[0m")
+			case isGreen:
+				output.WriteString("\x1b[92m██\x1b[0m")
+			default:
+				output.WriteString("\x1b[91m██\x1b[0m")
+			}
+		}
+		output.WriteString("|\n")
+	}
+
+	output.WriteString("  +----------------+\n\n")
+}
+
+/*
+ * @brief Read the characters back out of the stats display's RAM.
+ *
+ * @returns The characters, with any decimal points and the colon.
+ */
+func (p *terminalBus) statsText() string {
+
+	isSegment14 := p.statsKind == STATS_SEGMENT14
+	var output strings.Builder
+	for digit := 0; digit < 4; digit++ {
+		var glyph uint16
+		var hasDot bool
+		if isSegment14 {
+			// Each digit takes two bytes, low byte first
+			glyph = uint16(p.statsRam[digit*2]) | uint16(p.statsRam[digit*2+1])<<8
+			hasDot = glyph&ht16k33.SEGMENT14_DECIMAL_POINT != 0
+			glyph &= ^ht16k33.SEGMENT14_DECIMAL_POINT
+		} else {
+			// Digits take every other byte, with the colon in the middle
+			position := digit * 2
+			if digit > 1 {
+				position += 2
+			}
+
+			hasDot = p.statsRam[position]&ht16k33.SEGMENT7_DECIMAL_POINT != 0
+			glyph = uint16(p.statsRam[position] & ^ht16k33.SEGMENT7_DECIMAL_POINT)
+		}
+
+		output.WriteRune(segmentCharacter(glyph, isSegment14))
+		if hasDot {
+			output.WriteString(".")
+		}
+
+		if digit == 1 && !isSegment14 && p.statsRam[4]&ht16k33.SEGMENT7_COLON != 0 {
+			output.WriteString(":")
+		}
+	}
+
+	return output.String()
+}
+
+/*
+ * @brief Find the character a digit shows. Where characters share
+ *        segments, digits beat letters, and letters beat symbols.
+ *
+ * @param glyph:       The digit's segments, without the decimal point.
+ * @param isSegment14: `true` for a 14-segment digit, `false` for 7 segments.
+ *
+ * @returns The character, or '?' if the set has no match.
+ */
+func segmentCharacter(glyph uint16, isSegment14 bool) rune {
+
+	match := '?'
+	for i := range graphics.SEGMENT14_CHARSET {
+		segments := uint16(graphics.SEGMENT7_CHARSET[i])
+		if isSegment14 {
+			segments = graphics.SEGMENT14_CHARSET[i]
+		}
+
+		character := rune(i + 32)
+		if segments == glyph && (match == '?' || characterRank(character) < characterRank(match)) {
+			match = character
+		}
+	}
+
+	// The sets keep the degree sign in place of Ascii 127
+	if match == 127 {
+		match = '°'
+	}
+
+	return match
+}
+
+/*
+ * @brief Rank a character for `segmentCharacter()`.
+ *
+ * @param character: The character.
+ *
+ * @returns 0 for a digit, 1 for a letter, otherwise 2.
+ */
+func characterRank(character rune) int {
+
+	if unicode.IsDigit(character) {
+		return 0
+	}
+
+	if unicode.IsLetter(character) {
+		return 1
+	}
+
+	return 2
+}
+
+/*
+ * @brief Get the terminal representation of a sense LED.
+ *
+ * @param isOn:   Whether the LED is lit.
+ * @param colour: The LED's ANSI foreground colour code.
+ *
+ * @returns The LED as a string.
+ */
+func led(isOn bool, colour int) string {
+
+	if isOn {
+		return fmt.Sprintf("\x1b[%dm●\x1b[0m", colour)
+	}
+
+	return "\x1b[90m○\x1b[0m"
+}
@@ -0,0 +1,391 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ssd1306
+
+import (
+	"strings"
+	"time"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+const (
+	// Each pixel of the 8x8 frame is drawn as a square this many
+	// pixels across, filling the left of the display
+	SCREEN_SCALE     uint = 8
+	SCREEN_MAP_WIDTH uint = 8 * SCREEN_SCALE
+
+	// The status panel fills the rest, a line of text every 8 rows
+	SCREEN_PANEL_LEFT  uint = SCREEN_MAP_WIDTH + 2
+	SCREEN_PANEL_WIDTH uint = SSD1306_WIDTH - SCREEN_PANEL_LEFT
+	SCREEN_PANEL_LINES uint = SSD1306_HEIGHT / 8
+)
+
+/*
+ * The screen is one of the displays the game can draw on. It
+ * shows greyscale as patterns, and has room for the game's status
+ */
+var (
+	_ display.GreyscaleDisplay = (*Screen)(nil)
+	_ display.StatusDisplay    = (*Screen)(nil)
+)
+
+/*
+ * An SSD1306 standing in for an 8x8 LED matrix: the frame is scaled up
+ * to fill the left of the display, with a panel of text on the right.
+ * As on the matrices, (0,0) is the bottom left corner of the frame
+ */
+type Screen struct {
+	oled *SSD1306
+	// Frame buffer: each pixel's intensity, column by column
+	levels [8][8]uint
+	// The number of intensities shown
+	greyLevels uint
+	// The status panel's lines as last shown
+	status []string
+}
+
+/*
+ * @brief Convenience method to instantiate a Screen struct.
+ *
+ * @param oled: The display, which must be initialised.
+ *
+ * @returns The screen.
+ */
+func NewScreen(oled *SSD1306) Screen {
+
+	return Screen{
+		oled:       oled,
+		greyLevels: 4,
+	}
+}
+
+/*
+ * @brief Set the display's brightness.
+ *        The effect is immediate.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (very bright).
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) SetBrightness(brightness uint) error {
+
+	return p.oled.SetBrightness(brightness)
+}
+
+/*
+ * @brief Set how many intensities pixels can show. The OLEDs can't be
+ *        dimmed one by one, so intensities are drawn as patterns.
+ *
+ * @param levels: 2 for plain on and off, up to 4.
+ */
+func (p *Screen) SetGreyscale(levels uint) {
+
+	if levels < 2 {
+		levels = 2
+	} else if levels > 4 {
+		levels = 4
+	}
+
+	p.greyLevels = levels
+}
+
+/*
+ * @brief Get how many intensities pixels can show.
+ *
+ * @returns The number of levels, including off.
+ */
+func (p *Screen) GreyLevels() uint {
+
+	return p.greyLevels
+}
+
+/*
+ * @brief Set a pixel's intensity. With fewer than four levels,
+ *        the nearest level shown is used.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param level: `display.GREY_OFF`, `display.GREY_DIM`,
+ *               `display.GREY_MEDIUM` or `display.GREY_FULL`.
+ */
+func (p *Screen) PlotGrey(x uint, y uint, level uint) {
+
+	if x > 7 || y > 7 {
+		return
+	}
+
+	if level > display.GREY_FULL {
+		level = display.GREY_FULL
+	}
+
+	switch {
+	case p.greyLevels == 2 && level != display.GREY_OFF:
+		level = display.GREY_FULL
+	case p.greyLevels == 3 && level == display.GREY_MEDIUM:
+		level = display.GREY_DIM
+	}
+
+	p.levels[x][y] = level
+}
+
+/*
+ * @brief Get a pixel's intensity.
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns The pixel's level, or `display.GREY_OFF` if it's off the frame.
+ */
+func (p *Screen) GetGrey(x uint, y uint) uint {
+
+	if x > 7 || y > 7 {
+		return display.GREY_OFF
+	}
+
+	return p.levels[x][y]
+}
+
+/*
+ * @brief Does the display need `Refresh()` calls? The OLEDs
+ *        hold their image, so it never does.
+ *
+ * @returns `false`.
+ */
+func (p *Screen) NeedsRefresh() bool {
+
+	return false
+}
+
+/*
+ * @brief Keep the display refreshed. The OLEDs hold their image,
+ *        so there's nothing to do.
+ *
+ * @param now: The time now.
+ *
+ * @returns `nil`.
+ */
+func (p *Screen) Refresh(now time.Time) error {
+
+	return nil
+}
+
+/*
+ * @brief Turn a specific pixel of the frame on or off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param isSet: `true` to light the pixel, `false` to clear it.
+ */
+func (p *Screen) Plot(x uint, y uint, isSet bool) {
+
+	if isSet {
+		p.PlotGrey(x, y, display.GREY_FULL)
+	} else {
+		p.PlotGrey(x, y, display.GREY_OFF)
+	}
+}
+
+/*
+ * @brief Is a specific pixel of the frame lit?
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `true` if the pixel is set, `false` if it's clear
+ *          or off the frame.
+ */
+func (p *Screen) Get(x uint, y uint) bool {
+
+	return p.GetGrey(x, y) != display.GREY_OFF
+}
+
+/*
+ * @brief Write a graphic pattern to the frame buffer and the display.
+ *
+ * @param sprite: A graphic stored as an [8]byte array.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) DrawSprite(sprite *graphics.Sprite) error {
+
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			p.Plot(x, y, sprite[x]&(1<<y) != 0)
+		}
+	}
+
+	return p.Draw()
+}
+
+/*
+ * @brief Scroll a text string across the frame. Text that fits
+ *        on the frame is shown in the middle, without scrolling.
+ *
+ * @param text: The string to scroll.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) Print(text string) error {
+
+	scroller := display.NewScroller(p, text)
+	for !scroller.IsDone() {
+		if err := scroller.Step(time.Now()); err != nil {
+			return err
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+/*
+ * @brief Clear the frame buffer. The status panel is kept.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (p *Screen) Clear() {
+
+	p.levels = [8][8]uint{}
+}
+
+/*
+ * @brief Scale the frame buffer up onto the display, and update it.
+ *        Dimmed pixels are drawn as a pattern: half the pixels of
+ *        the square for medium, a quarter for dim.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) Draw() error {
+
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			// Row 7 of the frame is at the top of the display
+			left := x * SCREEN_SCALE
+			top := (7 - y) * SCREEN_SCALE
+			level := p.levels[x][y]
+			for i := uint(0); i < SCREEN_SCALE; i++ {
+				for j := uint(0); j < SCREEN_SCALE; j++ {
+					var isSet bool
+					switch level {
+					case display.GREY_FULL:
+						isSet = true
+					case display.GREY_MEDIUM:
+						isSet = (i+j)%2 == 0
+					case display.GREY_DIM:
+						isSet = i%2 == 0 && j%2 == 0
+					}
+
+					p.oled.Plot(left+i, top+j, isSet)
+				}
+			}
+		}
+	}
+
+	return p.oled.Draw()
+}
+
+/*
+ * @brief Display a series of 8x8 frames on the display.
+ *
+ * @param sequence:           A slice containing all the frames in order.
+ * @param frameCount:         The number of 8x8 frames in the sequence.
+ * @param interstitialPeriod: The time in ms between frames.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error {
+
+	count := 0
+	for {
+		frame := graphics.Sprite{}
+		copy(frame[:], sequence[count:count+8])
+		if err := p.DrawSprite(&frame); err != nil {
+			return err
+		}
+
+		time.Sleep(time.Millisecond * time.Duration(interstitialPeriod))
+
+		count += 8
+		if count >= (frameCount * 8) {
+			break
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Show lines of text in the status panel, and update the
+ *        display. Lines too long for the panel are wrapped between
+ *        words; any lines that don't fit are left out.
+ *
+ * @param lines: The lines, top first.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *Screen) ShowStatus(lines []string) error {
+
+	// Only redraw the panel when the text changes
+	if p.status != nil && strings.Join(lines, "\n") == strings.Join(p.status, "\n") {
+		return nil
+	}
+
+	p.status = append(p.status[:0], lines...)
+	p.oled.Fill(SCREEN_PANEL_LEFT, 0, SCREEN_PANEL_WIDTH, SSD1306_HEIGHT, false)
+
+	row := uint(0)
+	for _, line := range wrapLines(lines, int(SCREEN_PANEL_WIDTH)) {
+		if row == SCREEN_PANEL_LINES {
+			break
+		}
+
+		p.oled.Text(SCREEN_PANEL_LEFT, row*8, line)
+		row += 1
+	}
+
+	return p.oled.Draw()
+}
+
+/*
+ * @brief Break lines of text so that each fits a width, between
+ *        words where it can.
+ *
+ * @param lines: The lines.
+ * @param width: The width in pixels.
+ *
+ * @returns The wrapped lines.
+ */
+func wrapLines(lines []string, width int) []string {
+
+	wrapped := []string{}
+	for _, line := range lines {
+		current := ""
+		for _, word := range strings.Fields(line) {
+			next := word
+			if current != "" {
+				next = current + " " + word
+			}
+
+			if current != "" && len(display.TextColumns(next)) > width {
+				wrapped = append(wrapped, current)
+				next = word
+			}
+
+			current = next
+		}
+
+		wrapped = append(wrapped, current)
+	}
+
+	return wrapped
+}
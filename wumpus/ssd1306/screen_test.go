@@ -0,0 +1,101 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ssd1306
+
+import (
+	"reflect"
+	"testing"
+	"wumpus/display"
+)
+
+func TestScreenDraw(t *testing.T) {
+
+	oled, bus := newTestOled(t)
+	screen := NewScreen(oled)
+
+	// The frame's bottom left pixel is the display's
+	// bottom left 8x8 square: the bottom page's first columns
+	screen.Plot(0, 0, true)
+	_ = screen.Draw()
+	full := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	checkWrites(t, bus, pageWrites(7, 0, full...)...)
+
+	// Dimmed pixels are patterns: top left is the top page.
+	// The dim square's last column is blank, so isn't sent
+	screen.Clear()
+	screen.PlotGrey(0, 7, display.GREY_MEDIUM)
+	screen.PlotGrey(1, 7, display.GREY_DIM)
+	_ = screen.Draw()
+	medium := []byte{0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA, 0x55, 0xAA}
+	dim := []byte{0x55, 0x00, 0x55, 0x00, 0x55, 0x00, 0x55}
+	expected := append(pageWrites(0, 0, append(medium, dim...)...), pageWrites(7, 0, make([]byte, 8)...)...)
+	checkWrites(t, bus, expected...)
+
+	// On plain on and off, every lit pixel is in full
+	screen.SetGreyscale(2)
+	screen.PlotGrey(0, 7, display.GREY_DIM)
+	if screen.GetGrey(0, 7) != display.GREY_FULL {
+		t.Errorf("dim pixel read back as %d", screen.GetGrey(0, 7))
+	}
+}
+
+func TestShowStatus(t *testing.T) {
+
+	oled, bus := newTestOled(t)
+	screen := NewScreen(oled)
+
+	// Each line is eight rows of text, right of the map. The
+	// middle column of "I" is lit for all but the bottom row
+	_ = screen.ShowStatus([]string{"I", "", "I"})
+	for _, top := range []uint{0, 16} {
+		for row := uint(0); row < 7; row++ {
+			if !oled.Get(SCREEN_PANEL_LEFT+1, top+row) {
+				t.Errorf("text missing at row %d", top+row)
+			}
+		}
+	}
+
+	if oled.Get(SCREEN_PANEL_LEFT+1, 8) || oled.Get(SCREEN_PANEL_LEFT-1, 0) {
+		t.Error("text where there should be none")
+	}
+
+	// The same lines again cost nothing
+	bus.Reset()
+	_ = screen.ShowStatus([]string{"I", "", "I"})
+	checkWrites(t, bus)
+
+	// The map is left alone
+	screen.Plot(0, 7, true)
+	_ = screen.Draw()
+	_ = screen.ShowStatus([]string{"W"})
+	if !oled.Get(0, 0) {
+		t.Error("status cleared the map")
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+
+	// The panel is 62 pixels wide: about ten narrow characters
+	tests := []struct {
+		lines    []string
+		expected []string
+	}{
+		{[]string{"Arrows 5"}, []string{"Arrows 5"}},
+		{[]string{"Wumpus is near you"}, []string{"Wumpus is", "near you"}},
+		{[]string{"Supercalifragilistic"}, []string{"Supercalifragilistic"}},
+		{[]string{"", "Bats"}, []string{"", "Bats"}},
+	}
+
+	for _, test := range tests {
+		if wrapped := wrapLines(test.lines, int(SCREEN_PANEL_WIDTH)); !reflect.DeepEqual(wrapped, test.expected) {
+			t.Errorf("%q wrapped as %q, expected %q", test.lines, wrapped, test.expected)
+		}
+	}
+}
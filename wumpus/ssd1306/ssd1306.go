@@ -0,0 +1,350 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ssd1306
+
+import (
+	"fmt"
+	"wumpus/display"
+)
+
+const (
+	SSD1306_ADDRESS uint8 = 0x3C
+	SSD1306_WIDTH   uint  = 128
+	SSD1306_HEIGHT  uint  = 64
+	SSD1306_PAGES   uint  = 8
+
+	// Control bytes: what follows is a command, or display RAM data
+	SSD1306_CONTROL_COMMAND uint8 = 0x00
+	SSD1306_CONTROL_DATA    uint8 = 0x40
+
+	SSD1306_CMD_DISPLAY_OFF        uint8 = 0xAE
+	SSD1306_CMD_DISPLAY_ON         uint8 = 0xAF
+	SSD1306_CMD_CLOCK_DIVIDE       uint8 = 0xD5
+	SSD1306_CMD_MULTIPLEX          uint8 = 0xA8
+	SSD1306_CMD_DISPLAY_OFFSET     uint8 = 0xD3
+	SSD1306_CMD_START_LINE         uint8 = 0x40
+	SSD1306_CMD_CHARGE_PUMP        uint8 = 0x8D
+	SSD1306_CMD_MEMORY_MODE        uint8 = 0x20
+	SSD1306_CMD_SEGMENT_REMAP      uint8 = 0xA1
+	SSD1306_CMD_COM_SCAN_DEC       uint8 = 0xC8
+	SSD1306_CMD_COM_PINS           uint8 = 0xDA
+	SSD1306_CMD_CONTRAST           uint8 = 0x81
+	SSD1306_CMD_PRECHARGE          uint8 = 0xD9
+	SSD1306_CMD_VCOM_DETECT        uint8 = 0xDB
+	SSD1306_CMD_RESUME_RAM         uint8 = 0xA4
+	SSD1306_CMD_NORMAL_DISPLAY     uint8 = 0xA6
+	SSD1306_CMD_COLUMN_ADDRESS     uint8 = 0x21
+	SSD1306_CMD_PAGE_ADDRESS       uint8 = 0x22
+	SSD1306_MEMORY_MODE_HORIZONTAL uint8 = 0x00
+)
+
+/*
+ * The host I2C bus: all the driver needs is to send bytes to
+ * the display. TinyGo's `*machine.I2C` satisfies this
+ */
+type Bus interface {
+	Tx(addr uint16, w, r []byte) error
+}
+
+/*
+ * A 128x64 SSD1306 OLED display. Unlike the LED matrices, (0,0) is
+ * the top left corner, as it is on most screens. The display RAM is
+ * arranged in eight pages of eight rows; each byte is a column of a
+ * page, with its top pixel in bit 0
+ */
+type SSD1306 struct {
+	// Host I2C bus
+	bus Bus
+	// Internal data: I2C address, brightness level
+	address    uint8
+	brightness uint
+	// Frame buffer, page by page
+	buffer [1024]byte
+	// Display RAM as last written, so that only changes are sent
+	sent   [1024]byte
+	isSent bool
+	// Working space for sending a page, kept to save garbage collection
+	txBuffer [129]byte
+}
+
+/*
+ * @brief Convenience method to instantiate an SSD1306 struct.
+ *
+ * @param bus:     The host I2C bus, eg. a TinyGo `*machine.I2C`.
+ *                 IMPORTANT This must be configured by the calling
+ *                           application BEFORE calling `init()` or any
+ *                           other SSD1306 method.
+ * @param address: The display's 7-bit I2C address. Defaults to `0x3C`
+ *                 if out of range.
+ *
+ * @returns The display, and an error if it did not respond.
+ */
+func New(bus Bus, address uint8) (SSD1306, error) {
+
+	if address < 8 || address > 0x77 {
+		address = SSD1306_ADDRESS
+	}
+
+	oled := SSD1306{
+		bus:        bus,
+		address:    address,
+		brightness: 15,
+	}
+
+	// Probe the display by reading its status byte
+	data := [1]byte{}
+	if err := bus.Tx(uint16(address), nil, data[:]); err != nil {
+		return oled, fmt.Errorf("no SSD1306 at 0x%02X: %w", address, err)
+	}
+
+	return oled, nil
+}
+
+/*
+ * @brief Set up the display's driver circuits, power it on, clear
+ *        the frame buffer and write the buffer to the display.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *SSD1306) Init() error {
+
+	// Set the clock, the panel's 64 rows and its wiring, and switch
+	// on the charge pump that drives the OLEDs from 3.3V. Rows and
+	// columns are flipped so that the RAM's first byte is top left
+	err := p.command(
+		SSD1306_CMD_DISPLAY_OFF,
+		SSD1306_CMD_CLOCK_DIVIDE, 0x80,
+		SSD1306_CMD_MULTIPLEX, byte(SSD1306_HEIGHT-1),
+		SSD1306_CMD_DISPLAY_OFFSET, 0x00,
+		SSD1306_CMD_START_LINE,
+		SSD1306_CMD_CHARGE_PUMP, 0x14,
+		SSD1306_CMD_MEMORY_MODE, SSD1306_MEMORY_MODE_HORIZONTAL,
+		SSD1306_CMD_SEGMENT_REMAP,
+		SSD1306_CMD_COM_SCAN_DEC,
+		SSD1306_CMD_COM_PINS, 0x12,
+		SSD1306_CMD_PRECHARGE, 0xF1,
+		SSD1306_CMD_VCOM_DETECT, 0x40,
+		SSD1306_CMD_RESUME_RAM,
+		SSD1306_CMD_NORMAL_DISPLAY,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err = p.SetBrightness(8); err != nil {
+		return err
+	}
+
+	p.Clear()
+	if err = p.Draw(); err != nil {
+		return err
+	}
+
+	return p.Power(true)
+}
+
+/*
+ * @brief Turn the display on or off. The frame is kept while it's off.
+ *
+ * @param isOn: `true` to enable the display, `false` to turn it off.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *SSD1306) Power(isOn bool) error {
+
+	if isOn {
+		return p.command(SSD1306_CMD_DISPLAY_ON)
+	}
+
+	return p.command(SSD1306_CMD_DISPLAY_OFF)
+}
+
+/*
+ * @brief Set the display's brightness.
+ *        The effect is immediate.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (very bright).
+ *                    Note that 0 does not turn off the display.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *SSD1306) SetBrightness(brightness uint) error {
+
+	if brightness > 15 {
+		brightness = 15
+	}
+
+	p.brightness = brightness
+	return p.command(SSD1306_CMD_CONTRAST, byte(brightness*17))
+}
+
+/*
+ * @brief Turn a specific pixel on or off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate, from the top.
+ * @param isSet: `true` to light the pixel, `false` to clear it.
+ */
+func (p *SSD1306) Plot(x uint, y uint, isSet bool) {
+
+	if x >= SSD1306_WIDTH || y >= SSD1306_HEIGHT {
+		return
+	}
+
+	index := (y>>3)*SSD1306_WIDTH + x
+	if isSet {
+		p.buffer[index] |= 1 << (y & 0x07)
+	} else {
+		p.buffer[index] &= ^(1 << (y & 0x07))
+	}
+}
+
+/*
+ * @brief Is a specific pixel in the frame buffer lit?
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate, from the top.
+ *
+ * @returns `true` if the pixel is set, `false` if it's clear
+ *          or off the display.
+ */
+func (p *SSD1306) Get(x uint, y uint) bool {
+
+	if x >= SSD1306_WIDTH || y >= SSD1306_HEIGHT {
+		return false
+	}
+
+	return p.buffer[(y>>3)*SSD1306_WIDTH+x]&(1<<(y&0x07)) != 0
+}
+
+/*
+ * @brief Light or clear a rectangle of pixels.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:      The left column.
+ * @param y:      The top row.
+ * @param width:  The number of columns.
+ * @param height: The number of rows.
+ * @param isSet:  `true` to light the pixels, `false` to clear them.
+ */
+func (p *SSD1306) Fill(x uint, y uint, width uint, height uint, isSet bool) {
+
+	for i := x; i < x+width; i++ {
+		for j := y; j < y+height; j++ {
+			p.Plot(i, j, isSet)
+		}
+	}
+}
+
+/*
+ * @brief Write a line of text into the frame buffer, in the
+ *        matrices' 8-pixel font. Text that runs off the right
+ *        edge is cut off.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ *
+ * @param x:    The left column of the first character.
+ * @param y:    The top row of the text.
+ * @param text: The text.
+ *
+ * @returns The column after the text.
+ */
+func (p *SSD1306) Text(x uint, y uint, text string) uint {
+
+	// The font's columns have their top pixel in bit 7
+	for _, column := range display.TextColumns(text) {
+		for row := uint(0); row < 8; row++ {
+			p.Plot(x, y+row, column&(0x80>>row) != 0)
+		}
+
+		x += 1
+	}
+
+	return x
+}
+
+/*
+ * @brief Clear the internal frame buffer.
+ *        Doesn't update the display -- call `Draw()` to do so.
+ */
+func (p *SSD1306) Clear() {
+
+	for i := range p.buffer {
+		p.buffer[i] = 0x00
+	}
+}
+
+/*
+ * @brief Write the internal frame buffer to the display. Only the
+ *        columns of each page that have changed are sent.
+ *
+ * @returns An error if the display could not be reached.
+ */
+func (p *SSD1306) Draw() error {
+
+	for page := uint(0); page < SSD1306_PAGES; page++ {
+		start := page * SSD1306_WIDTH
+		columns := p.buffer[start : start+SSD1306_WIDTH]
+		sent := p.sent[start : start+SSD1306_WIDTH]
+
+		// Find the run of columns that differ, or take them
+		// all if the display RAM's contents aren't known
+		first, last := 0, int(SSD1306_WIDTH)-1
+		if p.isSent {
+			for first <= last && columns[first] == sent[first] {
+				first += 1
+			}
+
+			for last >= first && columns[last] == sent[last] {
+				last -= 1
+			}
+
+			if first > last {
+				continue
+			}
+		}
+
+		// Point the display at the run, then send it
+		err := p.command(
+			SSD1306_CMD_COLUMN_ADDRESS, byte(first), byte(last),
+			SSD1306_CMD_PAGE_ADDRESS, byte(page), byte(page),
+		)
+		if err == nil {
+			p.txBuffer[0] = SSD1306_CONTROL_DATA
+			copy(p.txBuffer[1:], columns[first:last+1])
+			err = p.bus.Tx(uint16(p.address), p.txBuffer[:last-first+2], nil)
+		}
+
+		if err != nil {
+			// Whatever got through, send it all next time
+			p.isSent = false
+			return err
+		}
+
+		copy(sent[first:last+1], columns[first:last+1])
+	}
+
+	p.isSent = true
+	return nil
+}
+
+/*
+ * @brief Send one or more commands, with their arguments.
+ *
+ * @param commands: The command bytes.
+ *
+ * @returns The bus error, if any.
+ */
+func (p *SSD1306) command(commands ...byte) error {
+
+	p.txBuffer[0] = SSD1306_CONTROL_COMMAND
+	length := copy(p.txBuffer[1:], commands)
+	return p.bus.Tx(uint16(p.address), p.txBuffer[:length+1], nil)
+}
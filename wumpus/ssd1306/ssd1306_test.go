@@ -0,0 +1,200 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ssd1306
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+/*
+ * @brief Make a display on a FakeBus, set up, with nothing
+ *        recorded yet.
+ *
+ * @param t: The test.
+ *
+ * @returns The display and its bus.
+ */
+func newTestOled(t *testing.T) (*SSD1306, *FakeBus) {
+
+	bus := FakeBus{}
+	oled, err := New(&bus, SSD1306_ADDRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = oled.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	bus.Reset()
+	return &oled, &bus
+}
+
+/*
+ * @brief Make the writes that send a run of a page's columns.
+ *
+ * @param page:    The page.
+ * @param first:   The first column.
+ * @param columns: The columns' bytes.
+ *
+ * @returns The command write and the data write.
+ */
+func pageWrites(page byte, first byte, columns ...byte) [][]byte {
+
+	last := first + byte(len(columns)) - 1
+	return [][]byte{
+		{0x00, 0x21, first, last, 0x22, page, page},
+		append([]byte{0x40}, columns...),
+	}
+}
+
+/*
+ * @brief Check the writes recorded on a FakeBus, then forget them.
+ *
+ * @param t:        The test.
+ * @param bus:      The bus.
+ * @param expected: The data of each write expected, in order.
+ */
+func checkWrites(t *testing.T, bus *FakeBus, expected ...[]byte) {
+
+	t.Helper()
+	written := [][]byte{}
+	for _, transaction := range bus.Transactions {
+		if transaction.Address != uint16(SSD1306_ADDRESS) {
+			t.Errorf("write to 0x%02X", transaction.Address)
+		}
+
+		written = append(written, transaction.Data)
+	}
+
+	if len(expected) == 0 {
+		expected = [][]byte{}
+	}
+
+	if !reflect.DeepEqual(written, expected) {
+		t.Errorf("wrote % X, expected % X", written, expected)
+	}
+
+	bus.Reset()
+}
+
+func TestNewReportsMissingDisplay(t *testing.T) {
+
+	bus := FakeBus{Err: errors.New("no ack")}
+	if _, err := New(&bus, SSD1306_ADDRESS); err == nil {
+		t.Error("no error from a missing display")
+	}
+}
+
+func TestInit(t *testing.T) {
+
+	bus := FakeBus{}
+	oled, _ := New(&bus, SSD1306_ADDRESS)
+	if err := oled.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The setup commands, half brightness, every page
+	// in full, and then the display is switched on
+	expected := [][]byte{
+		{0x00, 0xAE, 0xD5, 0x80, 0xA8, 0x3F, 0xD3, 0x00, 0x40, 0x8D, 0x14, 0x20, 0x00,
+			0xA1, 0xC8, 0xDA, 0x12, 0xD9, 0xF1, 0xDB, 0x40, 0xA4, 0xA6},
+		{0x00, 0x81, 0x88},
+	}
+
+	for page := byte(0); page < 8; page++ {
+		expected = append(expected, pageWrites(page, 0, make([]byte, 128)...)...)
+	}
+
+	expected = append(expected, []byte{0x00, 0xAF})
+	checkWrites(t, &bus, expected...)
+}
+
+func TestPlot(t *testing.T) {
+
+	oled, bus := newTestOled(t)
+
+	// Row 10 is bit 2 of the second page, counting from the top
+	oled.Plot(3, 10, true)
+	oled.Plot(128, 0, true)
+	oled.Plot(0, 64, true)
+	_ = oled.Draw()
+	checkWrites(t, bus, pageWrites(1, 3, 0x04)...)
+
+	if !oled.Get(3, 10) || oled.Get(3, 11) || oled.Get(128, 0) {
+		t.Error("pixels read back wrongly")
+	}
+}
+
+func TestDrawSendsOnlyChanges(t *testing.T) {
+
+	oled, bus := newTestOled(t)
+
+	// Each page sends the run from its first change to its last
+	oled.Plot(5, 0, true)
+	oled.Plot(9, 0, true)
+	oled.Plot(127, 63, true)
+	_ = oled.Draw()
+	expected := append(pageWrites(0, 5, 0x01, 0x00, 0x00, 0x00, 0x01), pageWrites(7, 127, 0x80)...)
+	checkWrites(t, bus, expected...)
+
+	// Nothing has changed, so nothing is sent
+	_ = oled.Draw()
+	checkWrites(t, bus)
+
+	oled.Plot(9, 0, false)
+	_ = oled.Draw()
+	checkWrites(t, bus, pageWrites(0, 9, 0x00)...)
+}
+
+func TestDrawResendsAfterError(t *testing.T) {
+
+	oled, bus := newTestOled(t)
+
+	bus.Err = errors.New("no ack")
+	oled.Plot(0, 0, true)
+	if err := oled.Draw(); err == nil {
+		t.Fatal("no error from a failed write")
+	}
+
+	// The RAM's contents are unknown, so every page is sent in full
+	bus.Err = nil
+	_ = oled.Draw()
+	if len(bus.Transactions) != 16 {
+		t.Fatalf("%d writes, expected 16", len(bus.Transactions))
+	}
+
+	first := append([]byte{0x40, 0x01}, make([]byte, 127)...)
+	if !bytes.Equal(bus.Transactions[1].Data, first) {
+		t.Errorf("first page sent as % X", bus.Transactions[1].Data)
+	}
+}
+
+func TestText(t *testing.T) {
+
+	oled, _ := newTestOled(t)
+
+	// "I" is three columns and a gap; the font's top pixel is bit 7
+	if next := oled.Text(10, 4, "I"); next != 14 {
+		t.Errorf("text ended at column %d, expected 14", next)
+	}
+
+	columns := []byte{0x82, 0xFE, 0x82, 0x00}
+	for i, column := range columns {
+		for row := uint(0); row < 8; row++ {
+			if oled.Get(10+uint(i), 4+row) != (column&(0x80>>row) != 0) {
+				t.Errorf("pixel %d,%d is wrong", 10+i, 4+row)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ws2812
+
+/*
+ * An Output that records every frame written to it rather than
+ * sending it, so the colour data the driver generates can be
+ * checked off-device
+ */
+type FakeOutput struct {
+	Frames [][]byte
+	// Set to make every write fail, as if the panel were unreachable
+	Err error
+}
+
+/*
+ * @brief Record a frame.
+ *
+ * @param data: The colour data, three bytes for each LED.
+ *
+ * @returns `Err`, which is `nil` unless a failure is being faked.
+ */
+func (o *FakeOutput) WritePixels(data []byte) error {
+
+	if o.Err != nil {
+		return o.Err
+	}
+
+	// Take a copy: the driver reuses its buffer
+	frame := make([]byte, len(data))
+	copy(frame, data)
+	o.Frames = append(o.Frames, frame)
+	return nil
+}
+
+/*
+ * @brief Get a LED's colour from the last frame recorded.
+ *
+ * @param led: The LED's place in the chain.
+ *
+ * @returns The colour, or 0 if there is no frame or no such LED.
+ */
+func (o *FakeOutput) Pixel(led uint) Colour {
+
+	if len(o.Frames) == 0 {
+		return 0
+	}
+
+	frame := o.Frames[len(o.Frames)-1]
+	if int(led*3+2) >= len(frame) {
+		return 0
+	}
+
+	// The data is green, red then blue
+	return Colour(frame[led*3+1])<<16 | Colour(frame[led*3])<<8 | Colour(frame[led*3+2])
+}
+
+/*
+ * @brief Discard all recorded frames.
+ */
+func (o *FakeOutput) Reset() {
+
+	o.Frames = nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ws2812
+
+const (
+	// The SPI clock the output needs: each bit the LEDs take is three
+	// SPI bits, high for one (0) or two (1), then low -- about 0.4us
+	// or 0.8us of a 1.25us period
+	WS2812_SPI_FREQUENCY uint32 = 2400000
+
+	// Low bytes sent after the data, long enough
+	// for the LEDs to latch it: over 300us
+	WS2812_SPI_LATCH_BYTES int = 100
+)
+
+/*
+ * The host SPI bus: all the output needs is to send bytes to
+ * the LEDs. TinyGo's `*machine.SPI` satisfies this
+ */
+type Bus interface {
+	Tx(w, r []byte) error
+}
+
+/*
+ * An Output that drives the LEDs' data line from an SPI bus' data out
+ * pin, running at `WS2812_SPI_FREQUENCY`, by shaping each bit the LEDs
+ * take out of three SPI bits. The SPI clock pin isn't used
+ */
+type SPIOutput struct {
+	bus Bus
+	// The encoded data, kept to save garbage collection
+	txBuffer []byte
+}
+
+/*
+ * @brief Convenience method to instantiate an SPIOutput struct.
+ *
+ * @param bus: The host SPI bus, eg. a TinyGo `*machine.SPI`.
+ *             IMPORTANT This must be configured by the calling
+ *                       application, at `WS2812_SPI_FREQUENCY`,
+ *                       BEFORE the output is used.
+ *
+ * @returns The output.
+ */
+func NewSPIOutput(bus Bus) SPIOutput {
+
+	return SPIOutput{
+		bus: bus,
+	}
+}
+
+/*
+ * @brief Send colour data to the LEDs.
+ *
+ * @param data: The data, three bytes for each LED.
+ *
+ * @returns The bus error, if any.
+ */
+func (o *SPIOutput) WritePixels(data []byte) error {
+
+	// Each byte becomes 24 SPI bits, or three bytes
+	length := len(data)*3 + WS2812_SPI_LATCH_BYTES
+	if len(o.txBuffer) != length {
+		o.txBuffer = make([]byte, length)
+	}
+
+	for i, value := range data {
+		bits := uint32(0)
+		for bit := 7; bit >= 0; bit-- {
+			if value&(1<<uint(bit)) != 0 {
+				bits = bits<<3 | 0x06
+			} else {
+				bits = bits<<3 | 0x04
+			}
+		}
+
+		o.txBuffer[i*3] = byte(bits >> 16)
+		o.txBuffer[i*3+1] = byte(bits >> 8)
+		o.txBuffer[i*3+2] = byte(bits)
+	}
+
+	return o.bus.Tx(o.txBuffer, nil)
+}
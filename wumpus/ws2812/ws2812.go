@@ -0,0 +1,468 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ws2812
+
+import (
+	"time"
+	"wumpus/display"
+	"wumpus/graphics"
+)
+
+const (
+	WS2812_PIXELS uint = 64
+
+	// How the panel's LEDs are chained: LED 0 is at the bottom left,
+	// and each row runs left to right, or rows alternate direction
+	WS2812_LAYOUT_PROGRESSIVE uint = 0
+	WS2812_LAYOUT_SERPENTINE  uint = 1
+)
+
+/*
+ * The panel is one of the displays the game can draw on,
+ * and it can show every colour
+ */
+var _ display.FullColourDisplay = (*WS2812)(nil)
+
+/*
+ * Where the driver sends the panel's colour data: three bytes for each
+ * LED, green, red then blue, in chain order. `SPIOutput` sends it to the
+ * LEDs; `FakeOutput` records it so that it can be checked off-device
+ */
+type Output interface {
+	WritePixels(data []byte) error
+}
+
+/*
+ * An RGB colour, as 0xRRGGBB
+ */
+type Colour uint32
+
+/*
+ * How the display's colours appear on the LEDs. Plain pixels -- sprites,
+ * text and `Plot()` -- are lit in the ink colour, on the paper colour
+ */
+type Palette struct {
+	// Each display colour's RGB value, eg. `Colours[display.COLOUR_BLUE]`
+	Colours [display.COLOUR_COUNT]Colour
+	// The display colours plain pixels are shown in
+	Ink   uint
+	Paper uint
+}
+
+/*
+ * The palette a panel starts with: white on black
+ */
+var DEFAULT_PALETTE Palette = Palette{
+	Colours: [display.COLOUR_COUNT]Colour{
+		0x000000, // Off
+		0x00FF00, // Green
+		0xFF0000, // Red
+		0xFFA000, // Yellow
+		0x0030FF, // Blue
+		0xFFFFFF, // White
+	},
+	Ink:   display.COLOUR_WHITE,
+	Paper: display.COLOUR_OFF,
+}
+
+/*
+ * An 8x8 panel of WS2812 RGB LEDs, aka NeoPixels.
+ * (0,0) is the bottom left corner
+ */
+type WS2812 struct {
+	// Where the colour data goes
+	output Output
+	// Frame buffer: each pixel's display colour, column by column
+	buffer [8][8]uint
+	// Internal data: brightness level, palette, and how the
+	// LEDs are chained
+	brightness uint
+	palette    Palette
+	layout     uint
+	// Colour data as last sent, so that an unchanged frame isn't sent
+	data   [WS2812_PIXELS * 3]byte
+	sent   [WS2812_PIXELS * 3]byte
+	isSent bool
+	// Orientation: applied as the frame buffer is sent
+	rotation    uint
+	isMirroredX bool
+	isMirroredY bool
+}
+
+/*
+ * @brief Convenience method to instantiate a WS2812 struct.
+ *
+ * @param output: Where to send the colour data, eg. an `SPIOutput`.
+ *
+ * @returns The panel.
+ */
+func New(output Output) WS2812 {
+
+	return WS2812{
+		output:     output,
+		brightness: 15,
+		palette:    DEFAULT_PALETTE,
+		layout:     WS2812_LAYOUT_PROGRESSIVE,
+	}
+}
+
+/*
+ * @brief Convenience method to set a default brightness, clear
+ *        the frame buffer and write the buffer to the panel.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) Init() error {
+
+	if err := p.SetBrightness(8); err != nil {
+		return err
+	}
+
+	p.Clear()
+	return p.Draw()
+}
+
+/*
+ * @brief Set how the panel's LEDs are chained.
+ *        Takes effect at the next `Draw()`.
+ *
+ * @param layout: `WS2812_LAYOUT_PROGRESSIVE` or `WS2812_LAYOUT_SERPENTINE`.
+ */
+func (p *WS2812) SetLayout(layout uint) {
+
+	p.layout = layout
+}
+
+/*
+ * @brief Set how the panel is mounted, so that (0,0) stays at the
+ *        bottom left as the viewer sees it. Takes effect at the
+ *        next `Draw()`.
+ *
+ * @param rotation:    `display.ROTATE_0`, `display.ROTATE_90`,
+ *                     `display.ROTATE_180` or `display.ROTATE_270`.
+ * @param isMirroredX: `true` to flip the image left to right.
+ * @param isMirroredY: `true` to flip the image top to bottom.
+ */
+func (p *WS2812) SetOrientation(rotation uint, isMirroredX bool, isMirroredY bool) {
+
+	p.rotation = rotation & 0x03
+	p.isMirroredX = isMirroredX
+	p.isMirroredY = isMirroredY
+}
+
+/*
+ * @brief Set the colours the panel shows. Takes effect
+ *        at the next `Draw()`.
+ *
+ * @param palette: The colours, eg. `DEFAULT_PALETTE` with
+ *                 a different ink.
+ */
+func (p *WS2812) SetPalette(palette Palette) {
+
+	if palette.Ink >= display.COLOUR_COUNT {
+		palette.Ink = display.COLOUR_WHITE
+	}
+
+	if palette.Paper >= display.COLOUR_COUNT {
+		palette.Paper = display.COLOUR_OFF
+	}
+
+	p.palette = palette
+}
+
+/*
+ * @brief Get the colours the panel shows.
+ *
+ * @returns The palette.
+ */
+func (p *WS2812) Palette() Palette {
+
+	return p.palette
+}
+
+/*
+ * @brief Set the panel's brightness. Takes effect at the next `Draw()`.
+ *
+ * @param brightness: A value between 0 (dim) and 15 (full).
+ *                    Note that 0 does not turn off the panel.
+ *
+ * @returns `nil` -- nothing is sent.
+ */
+func (p *WS2812) SetBrightness(brightness uint) error {
+
+	if brightness > 15 {
+		brightness = 15
+	}
+
+	p.brightness = brightness
+	return nil
+}
+
+/*
+ * @brief Does the panel show colour? It does.
+ *
+ * @returns `true`.
+ */
+func (p *WS2812) IsBicolor() bool {
+
+	return true
+}
+
+/*
+ * @brief Does the panel show every colour? It does.
+ *
+ * @returns `true`.
+ */
+func (p *WS2812) IsFullColour() bool {
+
+	return true
+}
+
+/*
+ * @brief Set a specific pixel's colour.
+ *        Doesn't update the panel -- call `Draw()` to do so.
+ *
+ * @param x:      The pixel's X co-ordinate.
+ * @param y:      The pixel's Y co-ordinate.
+ * @param colour: A display colour, eg. `display.COLOUR_BLUE`.
+ */
+func (p *WS2812) PlotColour(x uint, y uint, colour uint) {
+
+	if x > 7 || y > 7 {
+		return
+	}
+
+	if colour >= display.COLOUR_COUNT {
+		colour = display.COLOUR_WHITE
+	}
+
+	p.buffer[x][y] = colour
+}
+
+/*
+ * @brief Get a specific pixel's colour from the frame buffer.
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns The pixel's display colour, or `display.COLOUR_OFF`
+ *          if it's off the panel.
+ */
+func (p *WS2812) GetColour(x uint, y uint) uint {
+
+	if x > 7 || y > 7 {
+		return display.COLOUR_OFF
+	}
+
+	return p.buffer[x][y]
+}
+
+/*
+ * @brief Turn a specific pixel to the ink or paper colour.
+ *        Doesn't update the panel -- call `Draw()` to do so.
+ *
+ * @param x:     The pixel's X co-ordinate.
+ * @param y:     The pixel's Y co-ordinate.
+ * @param isSet: `true` to light the pixel, `false` to clear it.
+ */
+func (p *WS2812) Plot(x uint, y uint, isSet bool) {
+
+	if isSet {
+		p.PlotColour(x, y, p.palette.Ink)
+	} else {
+		p.PlotColour(x, y, p.palette.Paper)
+	}
+}
+
+/*
+ * @brief Is a specific pixel in the frame buffer lit?
+ *
+ * @param x: The pixel's X co-ordinate.
+ * @param y: The pixel's Y co-ordinate.
+ *
+ * @returns `true` if the pixel isn't the paper colour, `false`
+ *          if it is or it's off the panel.
+ */
+func (p *WS2812) Get(x uint, y uint) bool {
+
+	if x > 7 || y > 7 {
+		return false
+	}
+
+	return p.buffer[x][y] != p.palette.Paper
+}
+
+/*
+ * @brief Write a graphic pattern to the frame buffer, in the
+ *        palette's ink and paper colours, and update the panel.
+ *
+ * @param sprite: A graphic stored as an [8]byte array.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) DrawSprite(sprite *graphics.Sprite) error {
+
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			p.Plot(x, y, sprite[x]&(1<<y) != 0)
+		}
+	}
+
+	return p.Draw()
+}
+
+/*
+ * @brief Write a colour graphic to the frame buffer and update the
+ *        panel. Pixels in both layers are yellow.
+ *
+ * @param sprite: The graphic.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) DrawColourSprite(sprite *graphics.ColourSprite) error {
+
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			colour := p.palette.Paper
+			isGreen := sprite.Green[x]&(1<<y) != 0
+			isRed := sprite.Red[x]&(1<<y) != 0
+			switch {
+			case isGreen && isRed:
+				colour = display.COLOUR_YELLOW
+			case isGreen:
+				colour = display.COLOUR_GREEN
+			case isRed:
+				colour = display.COLOUR_RED
+			}
+
+			p.buffer[x][y] = colour
+		}
+	}
+
+	return p.Draw()
+}
+
+/*
+ * @brief Scroll a text string across the panel. Text that fits
+ *        on the panel is shown in the middle, without scrolling.
+ *
+ * @param text: The string to scroll.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) Print(text string) error {
+
+	scroller := display.NewScroller(p, text)
+	for !scroller.IsDone() {
+		if err := scroller.Step(time.Now()); err != nil {
+			return err
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+/*
+ * @brief Set the frame buffer to the paper colour.
+ *        Doesn't update the panel -- call `Draw()` to do so.
+ */
+func (p *WS2812) Clear() {
+
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			p.buffer[x][y] = p.palette.Paper
+		}
+	}
+}
+
+/*
+ * @brief Write the frame buffer to the panel, unless it's unchanged
+ *        since the last write: each pixel's colour from the palette,
+ *        scaled to the brightness, in the order the LEDs are chained.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) Draw() error {
+
+	for x := uint(0); x < 8; x++ {
+		for y := uint(0); y < 8; y++ {
+			// Find the LED: rows run from the bottom, and on a
+			// serpentine panel, odd rows run right to left
+			u, v := display.OrientPixel(x, y, p.rotation, p.isMirroredX, p.isMirroredY)
+			if p.layout == WS2812_LAYOUT_SERPENTINE && v%2 == 1 {
+				u = 7 - u
+			}
+
+			rgb := p.palette.Colours[p.buffer[x][y]]
+			index := (v*8 + u) * 3
+			p.data[index] = p.scale(byte(rgb >> 8))
+			p.data[index+1] = p.scale(byte(rgb >> 16))
+			p.data[index+2] = p.scale(byte(rgb))
+		}
+	}
+
+	if p.isSent && p.data == p.sent {
+		return nil
+	}
+
+	if err := p.output.WritePixels(p.data[:]); err != nil {
+		// Whatever got through, send it all next time
+		p.isSent = false
+		return err
+	}
+
+	p.sent = p.data
+	p.isSent = true
+	return nil
+}
+
+/*
+ * @brief Display a series of 8x8 frames on the panel.
+ *
+ * @param sequence:           A slice containing all the frames in order.
+ * @param frameCount:         The number of 8x8 frames in the sequence.
+ * @param interstitialPeriod: The time in ms between frames.
+ *
+ * @returns An error if the panel could not be written to.
+ */
+func (p *WS2812) AnimateSequence(sequence []byte, frameCount int, interstitialPeriod int) error {
+
+	count := 0
+	for {
+		frame := graphics.Sprite{}
+		copy(frame[:], sequence[count:count+8])
+		if err := p.DrawSprite(&frame); err != nil {
+			return err
+		}
+
+		time.Sleep(time.Millisecond * time.Duration(interstitialPeriod))
+
+		count += 8
+		if count >= (frameCount * 8) {
+			break
+		}
+	}
+
+	return nil
+}
+
+/*
+ * @brief Scale a colour channel to the brightness.
+ *
+ * @param value: The channel at full brightness.
+ *
+ * @returns The channel as sent.
+ */
+func (p *WS2812) scale(value byte) byte {
+
+	return byte(uint(value) * (p.brightness + 1) / 16)
+}
@@ -0,0 +1,188 @@
+/*
+ * Hunt the Wumpus for Raspberry Pi Pico
+ * Go version
+ *
+ * @authors     smittytone
+ * @copyright   2024, Tony Smith
+ * @licence     MIT
+ *
+ */
+package ws2812
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"wumpus/display"
+)
+
+/*
+ * An SPI bus that keeps the last transfer rather than sending it
+ */
+type recordingBus struct {
+	sent []byte
+	err  error
+}
+
+func (b *recordingBus) Tx(w, r []byte) error {
+
+	b.sent = append([]byte{}, w...)
+	return b.err
+}
+
+/*
+ * @brief Make a panel on a FakeOutput, at full brightness,
+ *        with nothing recorded yet.
+ *
+ * @returns The panel and its output.
+ */
+func newTestPanel() (*WS2812, *FakeOutput) {
+
+	output := FakeOutput{}
+	panel := New(&output)
+	_ = panel.SetBrightness(15)
+	return &panel, &output
+}
+
+func TestSPIOutputEncoding(t *testing.T) {
+
+	bus := recordingBus{}
+	output := NewSPIOutput(&bus)
+	if err := output.WritePixels([]byte{0x80, 0x01, 0x00, 0xFF}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A 1 is sent as 0b110 and a 0 as 0b100, most significant bit first
+	expected := []byte{0xD2, 0x49, 0x24, 0x92, 0x49, 0x26, 0x92, 0x49, 0x24, 0xDB, 0x6D, 0xB6}
+	if !bytes.Equal(bus.sent[:12], expected) {
+		t.Errorf("sent % X, expected % X", bus.sent[:12], expected)
+	}
+
+	// The line is then held low so the LEDs latch the data
+	latch := make([]byte, WS2812_SPI_LATCH_BYTES)
+	if len(bus.sent) != 12+WS2812_SPI_LATCH_BYTES || !bytes.Equal(bus.sent[12:], latch) {
+		t.Errorf("latch sent as % X", bus.sent[12:])
+	}
+}
+
+func TestSPIOutputEncodesEveryByte(t *testing.T) {
+
+	bus := recordingBus{}
+	output := NewSPIOutput(&bus)
+	for value := 0; value < 256; value++ {
+		_ = output.WritePixels([]byte{byte(value)})
+		bits := uint32(bus.sent[0])<<16 | uint32(bus.sent[1])<<8 | uint32(bus.sent[2])
+
+		// Each of the three SPI bits for an LED bit
+		// starts high, ends low, and carries the bit between
+		decoded := 0
+		for bit := 7; bit >= 0; bit-- {
+			group := (bits >> uint(bit*3)) & 0x07
+			if group != 0x04 && group != 0x06 {
+				t.Fatalf("0x%02X: bit %d sent as %03b", value, bit, group)
+			}
+
+			decoded = decoded<<1 | int(group>>1&0x01)
+		}
+
+		if decoded != value {
+			t.Fatalf("0x%02X sent as 0x%02X", value, decoded)
+		}
+	}
+}
+
+func TestSPIOutputReportsBusError(t *testing.T) {
+
+	bus := recordingBus{err: errors.New("bus fault")}
+	output := NewSPIOutput(&bus)
+	if err := output.WritePixels([]byte{0x00, 0x00, 0x00}); err != bus.err {
+		t.Errorf("got error %v, expected %v", err, bus.err)
+	}
+}
+
+func TestDrawSendsGRB(t *testing.T) {
+
+	panel, output := newTestPanel()
+	panel.PlotColour(0, 0, display.COLOUR_YELLOW)
+	panel.PlotColour(1, 0, display.COLOUR_BLUE)
+	if err := panel.Draw(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Frames) != 1 || len(output.Frames[0]) != int(WS2812_PIXELS)*3 {
+		t.Fatalf("sent %d frames", len(output.Frames))
+	}
+
+	// Green, red then blue for each LED
+	expected := []byte{0xA0, 0xFF, 0x00, 0x30, 0x00, 0xFF, 0x00, 0x00, 0x00}
+	if !bytes.Equal(output.Frames[0][:9], expected) {
+		t.Errorf("sent % X, expected % X", output.Frames[0][:9], expected)
+	}
+
+	if output.Pixel(0) != 0xFFA000 || output.Pixel(1) != 0x0030FF {
+		t.Errorf("LEDs show %06X and %06X", output.Pixel(0), output.Pixel(1))
+	}
+
+	// An unchanged frame isn't sent again
+	_ = panel.Draw()
+	if len(output.Frames) != 1 {
+		t.Errorf("unchanged frame sent again")
+	}
+}
+
+func TestDrawScalesBrightness(t *testing.T) {
+
+	panel, output := newTestPanel()
+	_ = panel.SetBrightness(7)
+	panel.PlotColour(0, 0, display.COLOUR_WHITE)
+	_ = panel.Draw()
+
+	if output.Pixel(0) != 0x7F7F7F {
+		t.Errorf("LED shows %06X, expected 7F7F7F", output.Pixel(0))
+	}
+}
+
+func TestDrawLayout(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		layout uint
+		x, y   uint
+		led    uint
+	}{
+		{"progressive row 0", WS2812_LAYOUT_PROGRESSIVE, 2, 0, 2},
+		{"progressive row 1", WS2812_LAYOUT_PROGRESSIVE, 2, 1, 10},
+		{"serpentine row 0", WS2812_LAYOUT_SERPENTINE, 2, 0, 2},
+		{"serpentine row 1", WS2812_LAYOUT_SERPENTINE, 2, 1, 13},
+		{"serpentine top", WS2812_LAYOUT_SERPENTINE, 0, 7, 63},
+	}
+
+	for _, test := range tests {
+		panel, output := newTestPanel()
+		panel.SetLayout(test.layout)
+		panel.Plot(test.x, test.y, true)
+		_ = panel.Draw()
+
+		for led := uint(0); led < WS2812_PIXELS; led++ {
+			isLit := output.Pixel(led) != 0
+			if isLit != (led == test.led) {
+				t.Errorf("%s: LED %d lit %t", test.name, led, isLit)
+			}
+		}
+	}
+}
+
+func TestDrawResendsAfterError(t *testing.T) {
+
+	panel, output := newTestPanel()
+	output.Err = errors.New("unreachable")
+	if err := panel.Init(); err == nil {
+		t.Fatal("no error from a failed write")
+	}
+
+	output.Err = nil
+	_ = panel.Draw()
+	if len(output.Frames) != 1 {
+		t.Errorf("%d frames sent, expected 1", len(output.Frames))
+	}
+}